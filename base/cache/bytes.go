@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"hash/maphash"
+	"sync"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/cache/internal"
+	"github.com/omnius-labs/core-go/base/clock"
+)
+
+const byteCacheShards = 256
+
+var ErrEntryTooLarge = internal.ErrEntryTooLarge
+
+// ByteCache keeps serialized values in preallocated byte slabs instead of Go pointers,
+// so that holding millions of entries adds almost nothing to GC mark work.
+// When a shard is full its oldest entries are overwritten.
+type ByteCache struct {
+	clock   clock.Clock
+	seed    maphash.Seed
+	shards  [byteCacheShards]byteCacheShard
+	timeout int64
+}
+
+type byteCacheShard struct {
+	ring  *internal.ByteRing
+	mutex sync.RWMutex
+}
+
+func NewByteCache(clock clock.Clock, maxBytes int, timeout time.Duration) *ByteCache {
+	c := &ByteCache{
+		clock:   clock,
+		seed:    maphash.MakeSeed(),
+		timeout: int64(timeout),
+	}
+	size := max(maxBytes/byteCacheShards, 1)
+	for i := range c.shards {
+		c.shards[i].ring = internal.NewByteRing(size)
+	}
+	return c
+}
+
+func (c *ByteCache) Get(key string) ([]byte, bool) {
+	hash := maphash.String(c.seed, key)
+	shard := c.shard(hash)
+
+	shard.mutex.RLock()
+	defer shard.mutex.RUnlock()
+
+	value, expireAt, ok := shard.ring.Get(hash, []byte(key))
+	if !ok {
+		return nil, false
+	}
+	if expireAt != 0 && c.clock.Now().UnixNano() >= expireAt {
+		return nil, false
+	}
+	return append([]byte(nil), value...), true
+}
+
+func (c *ByteCache) Set(key string, value []byte) error {
+	hash := maphash.String(c.seed, key)
+	shard := c.shard(hash)
+
+	var expireAt int64
+	if c.timeout > 0 {
+		expireAt = c.clock.Now().UnixNano() + c.timeout
+	}
+
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+
+	return shard.ring.Set(hash, []byte(key), value, expireAt)
+}
+
+func (c *ByteCache) Delete(key string) {
+	hash := maphash.String(c.seed, key)
+	shard := c.shard(hash)
+
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+
+	shard.ring.Delete(hash, []byte(key))
+}
+
+func (c *ByteCache) Len() int {
+	n := 0
+	for i := range c.shards {
+		shard := &c.shards[i]
+		shard.mutex.RLock()
+		n += shard.ring.Len()
+		shard.mutex.RUnlock()
+	}
+	return n
+}
+
+func (c *ByteCache) Clear() {
+	for i := range c.shards {
+		shard := &c.shards[i]
+		shard.mutex.Lock()
+		shard.ring.Clear()
+		shard.mutex.Unlock()
+	}
+}
+
+func (c *ByteCache) shard(hash uint64) *byteCacheShard {
+	return &c.shards[hash%byteCacheShards]
+}
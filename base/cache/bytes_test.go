@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ByteCache Test", func() {
+	It("set and get", func() {
+		bc := NewByteCache(clock.New(), 1<<20, 0)
+
+		Expect(bc.Set("a", []byte("value a"))).To(Succeed())
+		Expect(bc.Set("b", []byte("value b"))).To(Succeed())
+
+		ret, ok := bc.Get("a")
+		Expect(ok).To(BeTrue())
+		Expect(ret).To(Equal([]byte("value a")))
+
+		Expect(bc.Set("a", []byte("value a2"))).To(Succeed())
+		ret, ok = bc.Get("a")
+		Expect(ok).To(BeTrue())
+		Expect(ret).To(Equal([]byte("value a2")))
+		Expect(bc.Len()).To(Equal(2))
+
+		bc.Delete("a")
+		_, ok = bc.Get("a")
+		Expect(ok).To(BeFalse())
+		Expect(bc.Len()).To(Equal(1))
+	})
+
+	It("overwrites oldest entries", func() {
+		bc := NewByteCache(clock.New(), byteCacheShards*64, 0)
+
+		for i := 0; i < 1000; i++ {
+			Expect(bc.Set(strconv.Itoa(i), []byte("0123456789"))).To(Succeed())
+		}
+		Expect(bc.Len()).To(BeNumerically("<", 1000))
+
+		ret, ok := bc.Get("999")
+		Expect(ok).To(BeTrue())
+		Expect(ret).To(Equal([]byte("0123456789")))
+	})
+
+	It("rejects too large entry", func() {
+		bc := NewByteCache(clock.New(), byteCacheShards*64, 0)
+		Expect(bc.Set("a", make([]byte, 64))).To(MatchError(ErrEntryTooLarge))
+	})
+
+	It("expires", func() {
+		c := clock.NewFake(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+		bc := NewByteCache(c, 1<<20, 5*time.Second)
+
+		Expect(bc.Set("a", []byte("value a"))).To(Succeed())
+		c.Advance(4 * time.Second)
+		_, ok := bc.Get("a")
+		Expect(ok).To(BeTrue())
+		c.Advance(time.Second)
+		_, ok = bc.Get("a")
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("CodecCache Test", func() {
+	type item struct {
+		Name  string
+		Count int
+	}
+
+	cc := NewCodecCache[item](clock.New(), 1<<20, 0, JSONCodec[item]{})
+	fr := 0
+	f := func() (item, error) {
+		fr++
+		return item{Name: "a", Count: fr}, nil
+	}
+
+	It("first fetch", func() {
+		ret, err := cc.Get("a", f)
+		Expect(ret).To(Equal(item{Name: "a", Count: 1}))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("use cache", func() {
+		ret, err := cc.Get("a", f)
+		Expect(ret).To(Equal(item{Name: "a", Count: 1}))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("fetch error", func() {
+		ret, err := cc.Get("b", func() (item, error) {
+			return item{}, errors.New("error")
+		})
+		Expect(ret).To(Equal(item{}))
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var benchEntries = flag.Int("cache.entries", 1_000_000, "number of entries used by the GC benchmarks")
+
+func BenchmarkByteCacheSet(b *testing.B) {
+	bc := NewByteCache(clock.New(), 256<<20, 0)
+	value := make([]byte, 64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bc.Set(strconv.Itoa(i), value)
+	}
+}
+
+func BenchmarkByteCacheGet(b *testing.B) {
+	bc := NewByteCache(clock.New(), 256<<20, 0)
+	value := make([]byte, 64)
+	for i := 0; i < 100_000; i++ {
+		bc.Set(strconv.Itoa(i), value)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bc.Get(strconv.Itoa(i % 100_000))
+	}
+}
+
+func BenchmarkByteCacheGC(b *testing.B) {
+	n := *benchEntries
+	bc := NewByteCache(clock.New(), n*128, 0)
+	value := make([]byte, 64)
+	for i := 0; i < n; i++ {
+		bc.Set(fmt.Sprintf("key-%d", i), value)
+	}
+	benchmarkGC(b)
+	runtime.KeepAlive(bc)
+}
+
+func BenchmarkKeyValueCacheGC(b *testing.B) {
+	n := *benchEntries
+	kc := NewKeyValueCache[[]byte](clock.New(), n, time.Hour, time.Hour)
+	for i := 0; i < n; i++ {
+		kc.Get(fmt.Sprintf("key-%d", i), func() ([]byte, error) {
+			return make([]byte, 64), nil
+		})
+	}
+	benchmarkGC(b)
+	runtime.KeepAlive(kc)
+}
+
+func benchmarkGC(b *testing.B) {
+	runtime.GC()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		runtime.GC()
+	}
+}
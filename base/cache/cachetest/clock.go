@@ -0,0 +1,19 @@
+package cachetest
+
+import (
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+)
+
+var _ clock.Clock = (*Clock)(nil)
+
+// Clock is a fake clock that only moves when told to, and can be shared between goroutines.
+// Sleep advances it instead of waiting.
+type Clock struct {
+	*clock.FakeClock
+}
+
+func NewClock(now time.Time) *Clock {
+	return &Clock{clock.NewFake(now, clock.WithAutoAdvance())}
+}
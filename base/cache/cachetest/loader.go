@@ -0,0 +1,96 @@
+package cachetest
+
+import (
+	"context"
+)
+
+// Loader is a getter stub that records its calls and can be blocked to hold a load in flight.
+type Loader[T any] struct {
+	notifier
+	fn      func(call int) (T, error)
+	calls   int
+	waiting int
+	gate    chan struct{}
+}
+
+func NewLoader[T any](fn func(call int) (T, error)) *Loader[T] {
+	return &Loader[T]{fn: fn}
+}
+
+// NewCountingLoader returns a loader whose value is the number of times it has been called.
+func NewCountingLoader() *Loader[int] {
+	return NewLoader(func(call int) (int, error) {
+		return call, nil
+	})
+}
+
+func (l *Loader[T]) Get() (T, error) {
+	return l.GetContext(context.Background())
+}
+
+func (l *Loader[T]) GetContext(ctx context.Context) (T, error) {
+	var call int
+	var gate chan struct{}
+	l.update(func() {
+		l.calls++
+		call = l.calls
+		gate = l.gate
+		if gate != nil {
+			l.waiting++
+		}
+	})
+
+	if gate != nil {
+		var err error
+		select {
+		case <-gate:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		l.update(func() {
+			l.waiting--
+		})
+		if err != nil {
+			return *new(T), err
+		}
+	}
+
+	return l.fn(call)
+}
+
+func (l *Loader[T]) Calls() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.calls
+}
+
+// Block makes subsequent calls wait until Unblock is called.
+func (l *Loader[T]) Block() {
+	l.update(func() {
+		if l.gate == nil {
+			l.gate = make(chan struct{})
+		}
+	})
+}
+
+func (l *Loader[T]) Unblock() {
+	l.update(func() {
+		if l.gate != nil {
+			close(l.gate)
+			l.gate = nil
+		}
+	})
+}
+
+// AwaitBlocked waits until n calls are waiting on Unblock.
+func (l *Loader[T]) AwaitBlocked(n int) error {
+	return l.await(func() bool {
+		return l.waiting >= n
+	})
+}
+
+func (l *Loader[T]) AwaitCalls(n int) error {
+	return l.await(func() bool {
+		return l.calls >= n
+	})
+}
@@ -0,0 +1,51 @@
+package cachetest
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrTimeout = errors.New("timed out waiting for condition")
+
+// AwaitTimeout bounds every Await call, so that a broken expectation fails the test instead of hanging it.
+var AwaitTimeout = 10 * time.Second
+
+type notifier struct {
+	mutex   sync.Mutex
+	changed chan struct{}
+}
+
+func (n *notifier) update(f func()) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	f()
+	if n.changed != nil {
+		close(n.changed)
+		n.changed = nil
+	}
+}
+
+func (n *notifier) await(cond func() bool) error {
+	timer := time.NewTimer(AwaitTimeout)
+	defer timer.Stop()
+
+	for {
+		n.mutex.Lock()
+		if cond() {
+			n.mutex.Unlock()
+			return nil
+		}
+		if n.changed == nil {
+			n.changed = make(chan struct{})
+		}
+		changed := n.changed
+		n.mutex.Unlock()
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return ErrTimeout
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package cachetest
+
+import (
+	"github.com/omnius-labs/core-go/base/cache"
+)
+
+// LoadRecorder collects the load events of a cache, so that tests can wait for background refreshes to finish.
+type LoadRecorder struct {
+	notifier
+	events []cache.LoadEvent
+}
+
+func NewLoadRecorder() *LoadRecorder {
+	return &LoadRecorder{}
+}
+
+func (r *LoadRecorder) Option() cache.Option {
+	return cache.WithLoadHook(r.record)
+}
+
+func (r *LoadRecorder) Events() []cache.LoadEvent {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]cache.LoadEvent(nil), r.events...)
+}
+
+func (r *LoadRecorder) Refreshes() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.count(true)
+}
+
+// AwaitRefreshes waits until n background refreshes have completed, whether they were stored or not.
+func (r *LoadRecorder) AwaitRefreshes(n int) error {
+	return r.await(func() bool {
+		return r.count(true) >= n
+	})
+}
+
+// AwaitLoads waits until n loads of any kind have completed.
+func (r *LoadRecorder) AwaitLoads(n int) error {
+	return r.await(func() bool {
+		return len(r.events) >= n
+	})
+}
+
+func (r *LoadRecorder) record(event cache.LoadEvent) {
+	r.update(func() {
+		r.events = append(r.events, event)
+	})
+}
+
+func (r *LoadRecorder) count(refresh bool) int {
+	n := 0
+	for _, e := range r.events {
+		if e.Refresh == refresh {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,188 @@
+package cachetest
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/cache"
+)
+
+// Value is the value type used by Stress. Seq is unique and increases with every produced value.
+type Value struct {
+	Key string
+	Seq uint64
+	At  time.Time
+}
+
+type StressConfig struct {
+	Goroutines     int
+	Operations     int
+	Keys           int
+	Capacity       int
+	TimeoutRefresh time.Duration
+	TimeoutRotten  time.Duration
+	MaxAdvance     time.Duration
+	Seed           uint64
+}
+
+func DefaultStressConfig() StressConfig {
+	return StressConfig{
+		Goroutines:     8,
+		Operations:     1000,
+		Keys:           8,
+		Capacity:       4,
+		TimeoutRefresh: 5 * time.Second,
+		TimeoutRotten:  30 * time.Second,
+		MaxAdvance:     3 * time.Second,
+		Seed:           1,
+	}
+}
+
+type StressError struct {
+	Violations []string
+}
+
+func (e *StressError) Error() string {
+	return fmt.Sprintf("%d violations:\n%s", len(e.Violations), strings.Join(e.Violations, "\n"))
+}
+
+type stressOrigin struct {
+	key    string
+	loaded bool
+}
+
+type stressRead struct {
+	goroutine int
+	op        int
+	key       string
+	before    time.Time
+	value     Value
+	err       error
+}
+
+type stressRun struct {
+	cfg     StressConfig
+	clock   *Clock
+	cache   *cache.KeyValueCache[Value]
+	mutex   sync.Mutex
+	seq     uint64
+	origins map[uint64]stressOrigin
+}
+
+// Stress runs random concurrent Get, Invalidate, CompareAndSwap and clock advances against a KeyValueCache,
+// then checks the recorded reads:
+//   - every value was produced for the key it was read with,
+//   - a goroutine never observes an older value of a key after a newer one,
+//   - a loaded value is never served after it has gone rotten,
+//   - the number of entries never exceeds the capacity.
+func Stress(cfg StressConfig, opts ...cache.Option) error {
+	run := &stressRun{
+		cfg:     cfg,
+		clock:   NewClock(time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)),
+		origins: make(map[uint64]stressOrigin),
+	}
+	run.cache = cache.NewKeyValueCache[Value](run.clock, cfg.Capacity, cfg.TimeoutRefresh, cfg.TimeoutRotten, opts...)
+
+	reads := make([][]stressRead, cfg.Goroutines)
+	wg := &sync.WaitGroup{}
+	for g := 0; g < cfg.Goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			reads[g] = run.worker(g)
+		}(g)
+	}
+	wg.Wait()
+
+	var violations []string
+	for _, rs := range reads {
+		violations = append(violations, run.check(rs)...)
+	}
+	if len(violations) > 0 {
+		return &StressError{Violations: violations}
+	}
+	return nil
+}
+
+func (r *stressRun) worker(g int) []stressRead {
+	rnd := rand.New(rand.NewPCG(r.cfg.Seed, uint64(g)))
+	var reads []stressRead
+
+	for op := 0; op < r.cfg.Operations; op++ {
+		key := strconv.Itoa(rnd.IntN(r.cfg.Keys))
+		switch p := rnd.IntN(100); {
+		case p < 70:
+			before := r.clock.Now()
+			value, err := r.cache.Get(key, func() (Value, error) {
+				value := r.produce(key, true)
+				runtime.Gosched() // widen the window in which a load races with writes
+				return value, nil
+			})
+			reads = append(reads, stressRead{goroutine: g, op: op, key: key, before: before, value: value, err: err})
+			if n := r.cache.Len(); n > r.cfg.Capacity {
+				reads = append(reads, stressRead{goroutine: g, op: op, key: key, err: fmt.Errorf("%d entries exceed capacity %d", n, r.cfg.Capacity)})
+			}
+		case p < 80:
+			if r.cfg.MaxAdvance > 0 {
+				r.clock.Advance(time.Duration(rnd.Int64N(int64(r.cfg.MaxAdvance))))
+			}
+		case p < 90:
+			r.cache.Invalidate(key)
+		default:
+			// Swapping into an absent key is skipped: a value produced before the key was loaded
+			// and invalidated again would legitimately be older than what other goroutines have seen.
+			if _, version, ok := r.cache.Lookup(key); ok {
+				r.cache.CompareAndSwap(key, version, r.produce(key, false))
+			}
+		}
+	}
+
+	return reads
+}
+
+func (r *stressRun) produce(key string, loaded bool) Value {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.seq++
+	r.origins[r.seq] = stressOrigin{key: key, loaded: loaded}
+	return Value{Key: key, Seq: r.seq, At: r.clock.Now()}
+}
+
+func (r *stressRun) check(reads []stressRead) []string {
+	var violations []string
+	last := make(map[string]uint64)
+
+	for _, read := range reads {
+		prefix := fmt.Sprintf("goroutine %d op %d key %s:", read.goroutine, read.op, read.key)
+
+		if read.err != nil {
+			violations = append(violations, fmt.Sprintf("%s unexpected error: %v", prefix, read.err))
+			continue
+		}
+
+		r.mutex.Lock()
+		origin, ok := r.origins[read.value.Seq]
+		r.mutex.Unlock()
+		if !ok || origin.key != read.key || read.value.Key != read.key {
+			violations = append(violations, fmt.Sprintf("%s value %+v was not produced for this key", prefix, read.value))
+			continue
+		}
+
+		if read.value.Seq < last[read.key] {
+			violations = append(violations, fmt.Sprintf("%s value seq %d observed after seq %d", prefix, read.value.Seq, last[read.key]))
+		}
+		last[read.key] = max(last[read.key], read.value.Seq)
+
+		if origin.loaded && !read.before.Before(read.value.At.Add(r.cfg.TimeoutRotten)) {
+			violations = append(violations, fmt.Sprintf("%s value loaded at %s served at %s after going rotten", prefix, read.value.At, read.before))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	"github.com/pkg/errors"
+)
+
+type Codec[T any] interface {
+	Marshal(value T) ([]byte, error)
+	Unmarshal(data []byte) (T, error)
+}
+
+var _ Codec[any] = JSONCodec[any]{}
+
+type JSONCodec[T any] struct{}
+
+func (JSONCodec[T]) Marshal(value T) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (JSONCodec[T]) Unmarshal(data []byte) (T, error) {
+	var value T
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+// CodecCache is a typed view of a ByteCache that serializes values with a Codec.
+type CodecCache[T any] struct {
+	bytes *ByteCache
+	codec Codec[T]
+}
+
+func NewCodecCache[T any](clock clock.Clock, maxBytes int, timeout time.Duration, codec Codec[T]) *CodecCache[T] {
+	return &CodecCache[T]{
+		bytes: NewByteCache(clock, maxBytes, timeout),
+		codec: codec,
+	}
+}
+
+func (c *CodecCache[T]) Get(key string, getter func() (T, error)) (T, error) {
+	if data, ok := c.bytes.Get(key); ok {
+		value, err := c.codec.Unmarshal(data)
+		if err == nil {
+			return value, nil
+		}
+	}
+
+	value, err := getter()
+	if err != nil {
+		return *new(T), err
+	}
+
+	err = c.Set(key, value)
+	if err != nil && !errors.Is(err, ErrEntryTooLarge) {
+		return *new(T), err
+	}
+	return value, nil
+}
+
+func (c *CodecCache[T]) Set(key string, value T) error {
+	data, err := c.codec.Marshal(value)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal")
+	}
+	return c.bytes.Set(key, data)
+}
+
+func (c *CodecCache[T]) Delete(key string) {
+	c.bytes.Delete(key)
+}
+
+func (c *CodecCache[T]) Len() int {
+	return c.bytes.Len()
+}
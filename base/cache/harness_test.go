@@ -0,0 +1,129 @@
+package cache_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/cache"
+	"github.com/omnius-labs/core-go/base/cache/cachetest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Harness Test", func() {
+	It("awaits background refresh", func() {
+		c := cachetest.NewClock(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+		loader := cachetest.NewCountingLoader()
+		recorder := cachetest.NewLoadRecorder()
+		vc := cache.NewKeyValueCache[int](c, 2, 5*time.Second, 30*time.Second, recorder.Option())
+
+		ret, err := vc.Get("a", loader.Get)
+		Expect(ret).To(Equal(1))
+		Expect(err).NotTo(HaveOccurred())
+
+		c.Advance(10 * time.Second)
+		loader.Block()
+
+		ret, err = vc.Get("a", loader.Get)
+		Expect(ret).To(Equal(1))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loader.AwaitBlocked(1)).To(Succeed())
+
+		ret, err = vc.Get("a", loader.Get)
+		Expect(ret).To(Equal(1))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loader.Calls()).To(Equal(2))
+
+		loader.Unblock()
+		Expect(recorder.AwaitRefreshes(1)).To(Succeed())
+
+		ret, err = vc.Get("a", loader.Get)
+		Expect(ret).To(Equal(2))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.Events()).To(Equal([]cache.LoadEvent{
+			{Key: "a", Refresh: false, Stored: true},
+			{Key: "a", Refresh: true, Stored: true},
+		}))
+	})
+
+	It("reports discarded refresh", func() {
+		c := cachetest.NewClock(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+		loader := cachetest.NewCountingLoader()
+		recorder := cachetest.NewLoadRecorder()
+		vc := cache.NewKeyValueCache[int](c, 2, 5*time.Second, 30*time.Second, recorder.Option())
+
+		_, err := vc.Get("a", loader.Get)
+		Expect(err).NotTo(HaveOccurred())
+
+		c.Advance(10 * time.Second)
+		loader.Block()
+		_, err = vc.Get("a", loader.Get)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loader.AwaitBlocked(1)).To(Succeed())
+
+		vc.Invalidate("a")
+		loader.Unblock()
+		Expect(recorder.AwaitRefreshes(1)).To(Succeed())
+		Expect(recorder.Events()[1]).To(Equal(cache.LoadEvent{Key: "a", Refresh: true, Stored: false}))
+	})
+
+	It("loads a missing key without blocking other keys", func() {
+		c := cachetest.NewClock(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+		slow := cachetest.NewCountingLoader()
+		vc := cache.NewKeyValueCache[int](c, 2, 5*time.Second, 30*time.Second)
+
+		slow.Block()
+		done := make(chan int)
+		go func() {
+			ret, _ := vc.Get("a", slow.Get)
+			done <- ret
+		}()
+		Expect(slow.AwaitBlocked(1)).To(Succeed())
+
+		ret, err := vc.Get("b", func() (int, error) { return 10, nil })
+		Expect(ret).To(Equal(10))
+		Expect(err).NotTo(HaveOccurred())
+
+		vc.Invalidate("a")
+		slow.Unblock()
+		Expect(<-done).To(Equal(1))
+
+		_, _, ok := vc.Lookup("a")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("does not block a value cache caller on another caller's load", func() {
+		c := cachetest.NewClock(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+		loader := cachetest.NewCountingLoader()
+		vc := cache.NewValueCache[int](c, 5*time.Second, 30*time.Second)
+
+		loader.Block()
+		done := make(chan int)
+		go func() {
+			ret, _ := vc.Get(loader.Get)
+			done <- ret
+		}()
+		Expect(loader.AwaitBlocked(1)).To(Succeed())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := vc.GetContext(ctx, loader.GetContext)
+		Expect(err).To(MatchError(context.Canceled))
+
+		loader.Unblock()
+		Expect(<-done).To(Equal(1))
+		ret, err := vc.Get(loader.Get)
+		Expect(ret).To(Equal(1))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("stress", func() {
+		Expect(cachetest.Stress(cachetest.DefaultStressConfig())).To(Succeed())
+	})
+
+	It("stress with jitter and early refresh", func() {
+		cfg := cachetest.DefaultStressConfig()
+		cfg.Seed = 2
+		Expect(cachetest.Stress(cfg, cache.WithJitter(30), cache.WithEarlyRefresh(1))).To(Succeed())
+	})
+})
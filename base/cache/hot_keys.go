@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"container/heap"
+	"fmt"
+	"hash/maphash"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/omnius-labs/core-go/base/cache/internal"
+)
+
+// HotKeyTracker keeps the top N most accessed keys, counting accesses with a count-min sketch
+// so that memory stays bounded however many distinct keys are seen.
+type HotKeyTracker[K comparable] struct {
+	mutex  sync.Mutex
+	seed   maphash.Seed
+	sketch *internal.CountMinSketch
+	top    hotKeyHeap[K]
+	index  map[K]*hotKey[K]
+	n      int
+}
+
+type hotKey[K comparable] struct {
+	key   K
+	count uint32
+	pos   int
+}
+
+func NewHotKeyTracker[K comparable](n int) *HotKeyTracker[K] {
+	width := max(n*16, 1024)
+	return &HotKeyTracker[K]{
+		seed:   maphash.MakeSeed(),
+		sketch: internal.NewCountMinSketch(width, 4),
+		index:  make(map[K]*hotKey[K], n),
+		n:      n,
+	}
+}
+
+func (t *HotKeyTracker[K]) Record(key K) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	count := t.sketch.Add(hashKey(t.seed, key))
+
+	if hk, ok := t.index[key]; ok {
+		hk.count = count
+		heap.Fix(&t.top, hk.pos)
+		return
+	}
+
+	if len(t.top) < t.n {
+		hk := &hotKey[K]{key: key, count: count}
+		t.index[key] = hk
+		heap.Push(&t.top, hk)
+		return
+	}
+
+	if t.n > 0 && t.top[0].count < count {
+		hk := t.top[0]
+		delete(t.index, hk.key)
+		hk.key = key
+		hk.count = count
+		t.index[key] = hk
+		heap.Fix(&t.top, 0)
+	}
+}
+
+// Top returns the tracked keys, the most accessed first.
+func (t *HotKeyTracker[K]) Top() []K {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	sorted := append(hotKeyHeap[K](nil), t.top...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].count > sorted[j].count
+	})
+
+	keys := make([]K, len(sorted))
+	for i, hk := range sorted {
+		keys[i] = hk.key
+	}
+	return keys
+}
+
+// Decay halves all counts, so that keys that stopped being accessed are eventually replaced.
+func (t *HotKeyTracker[K]) Decay() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.sketch.Halve()
+	for _, hk := range t.top {
+		hk.count >>= 1
+	}
+}
+
+func (t *HotKeyTracker[K]) Reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.sketch.Reset()
+	t.top = nil
+	clear(t.index)
+}
+
+func hashKey[K comparable](seed maphash.Seed, key K) uint64 {
+	switch k := any(key).(type) {
+	case string:
+		return maphash.String(seed, k)
+	case int:
+		return maphash.String(seed, strconv.Itoa(k))
+	case int64:
+		return maphash.String(seed, strconv.FormatInt(k, 10))
+	case uint64:
+		return maphash.String(seed, strconv.FormatUint(k, 10))
+	default:
+		return maphash.String(seed, fmt.Sprint(k))
+	}
+}
+
+type hotKeyHeap[K comparable] []*hotKey[K]
+
+func (h hotKeyHeap[K]) Len() int           { return len(h) }
+func (h hotKeyHeap[K]) Less(i, j int) bool { return h[i].count < h[j].count }
+func (h hotKeyHeap[K]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].pos = i
+	h[j].pos = j
+}
+
+func (h *hotKeyHeap[K]) Push(x any) {
+	hk := x.(*hotKey[K])
+	hk.pos = len(*h)
+	*h = append(*h, hk)
+}
+
+func (h *hotKeyHeap[K]) Pop() any {
+	old := *h
+	hk := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return hk
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"strconv"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HotKeyTracker Test", func() {
+	It("keeps the most accessed keys", func() {
+		t := NewHotKeyTracker[string](3)
+		for i := 0; i < 1000; i++ {
+			t.Record(strconv.Itoa(i))
+		}
+		for i := 0; i < 10; i++ {
+			t.Record("hot1")
+			t.Record("hot2")
+		}
+		for i := 0; i < 20; i++ {
+			t.Record("hot1")
+			t.Record("hot3")
+		}
+		for i := 0; i < 5; i++ {
+			t.Record("hot3")
+		}
+		Expect(t.Top()).To(Equal([]string{"hot1", "hot3", "hot2"}))
+	})
+
+	It("decays", func() {
+		t := NewHotKeyTracker[int](1)
+		for i := 0; i < 8; i++ {
+			t.Record(1)
+		}
+		t.Decay()
+		t.Decay()
+		t.Decay()
+		for i := 0; i < 3; i++ {
+			t.Record(2)
+		}
+		Expect(t.Top()).To(Equal([]int{2}))
+	})
+
+	It("resets", func() {
+		t := NewHotKeyTracker[int](1)
+		t.Record(1)
+		t.Reset()
+		Expect(t.Top()).To(BeEmpty())
+	})
+})
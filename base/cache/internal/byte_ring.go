@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// ByteRing stores entries in a single preallocated byte slice that is overwritten from the oldest entry.
+// The index only holds integers, so neither the index nor the buffer has to be scanned by the GC.
+//
+// An entry is laid out as: keyLen(2) | valueLen(4) | expireAt(8) | key | value.
+type ByteRing struct {
+	buf    []byte
+	index  map[uint64]uint64
+	gen    uint64
+	offset uint64
+}
+
+const byteRingHeaderSize = 2 + 4 + 8
+
+var ErrEntryTooLarge = errors.New("entry is too large")
+
+func NewByteRing(size int) *ByteRing {
+	return &ByteRing{
+		buf:   make([]byte, size),
+		index: make(map[uint64]uint64),
+		gen:   1,
+	}
+}
+
+func (r *ByteRing) Set(hash uint64, key []byte, value []byte, expireAt int64) error {
+	size := uint64(byteRingHeaderSize + len(key) + len(value))
+	if len(key) > 0xffff || len(value) > 0xffffffff || size > uint64(len(r.buf)) {
+		return ErrEntryTooLarge
+	}
+
+	if r.offset+size > uint64(len(r.buf)) {
+		r.gen++
+		r.offset = 0
+		r.sweep()
+	}
+
+	p := r.buf[r.offset : r.offset+size]
+	binary.LittleEndian.PutUint16(p[0:], uint16(len(key)))
+	binary.LittleEndian.PutUint32(p[2:], uint32(len(value)))
+	binary.LittleEndian.PutUint64(p[6:], uint64(expireAt))
+	copy(p[byteRingHeaderSize:], key)
+	copy(p[byteRingHeaderSize+len(key):], value)
+
+	r.index[hash] = r.gen<<32 | r.offset
+	r.offset += size
+	return nil
+}
+
+// Get returns a slice that aliases the ring buffer; callers must copy it before the ring is written again.
+func (r *ByteRing) Get(hash uint64, key []byte) (value []byte, expireAt int64, ok bool) {
+	pos, ok := r.index[hash]
+	if !ok || !r.valid(pos) {
+		return nil, 0, false
+	}
+
+	offset := pos & 0xffffffff
+	p := r.buf[offset:]
+	keyLen := int(binary.LittleEndian.Uint16(p[0:]))
+	valueLen := int(binary.LittleEndian.Uint32(p[2:]))
+	expireAt = int64(binary.LittleEndian.Uint64(p[6:]))
+	if !bytes.Equal(p[byteRingHeaderSize:byteRingHeaderSize+keyLen], key) {
+		return nil, 0, false
+	}
+
+	start := byteRingHeaderSize + keyLen
+	return p[start : start+valueLen], expireAt, true
+}
+
+func (r *ByteRing) Delete(hash uint64, key []byte) {
+	if _, _, ok := r.Get(hash, key); ok {
+		delete(r.index, hash)
+	}
+}
+
+func (r *ByteRing) Len() int {
+	n := 0
+	for _, pos := range r.index {
+		if r.valid(pos) {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *ByteRing) Clear() {
+	clear(r.index)
+	r.gen++
+	r.offset = 0
+}
+
+// valid reports whether the entry at pos has not been overwritten since it was written.
+func (r *ByteRing) valid(pos uint64) bool {
+	gen := pos >> 32
+	offset := pos & 0xffffffff
+	return (gen == r.gen && offset < r.offset) || (gen+1 == r.gen && offset >= r.offset)
+}
+
+func (r *ByteRing) sweep() {
+	for hash, pos := range r.index {
+		if pos>>32+1 < r.gen {
+			delete(r.index, hash)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package internal
+
+// CountMinSketch estimates how often a hash has been added, never underestimating it.
+type CountMinSketch struct {
+	width    uint64
+	depth    int
+	counters []uint32
+}
+
+func NewCountMinSketch(width int, depth int) *CountMinSketch {
+	return &CountMinSketch{
+		width:    uint64(width),
+		depth:    depth,
+		counters: make([]uint32, width*depth),
+	}
+}
+
+// Add increments the counters of hash and returns its new estimate.
+func (s *CountMinSketch) Add(hash uint64) uint32 {
+	estimate := ^uint32(0)
+	for i := 0; i < s.depth; i++ {
+		idx := s.index(hash, i)
+		if s.counters[idx] < ^uint32(0) {
+			s.counters[idx]++
+		}
+		estimate = min(estimate, s.counters[idx])
+	}
+	return estimate
+}
+
+func (s *CountMinSketch) Estimate(hash uint64) uint32 {
+	estimate := ^uint32(0)
+	for i := 0; i < s.depth; i++ {
+		estimate = min(estimate, s.counters[s.index(hash, i)])
+	}
+	return estimate
+}
+
+// Halve ages all counters, so that keys that were hot a long time ago fade out.
+func (s *CountMinSketch) Halve() {
+	for i := range s.counters {
+		s.counters[i] >>= 1
+	}
+}
+
+func (s *CountMinSketch) Reset() {
+	clear(s.counters)
+}
+
+func (s *CountMinSketch) index(hash uint64, row int) uint64 {
+	h1 := hash
+	h2 := hash>>32 | 1
+	return uint64(row)*s.width + (h1+uint64(row)*h2)%s.width
+}
@@ -1,6 +1,8 @@
 package cache
 
 import (
+	"context"
+	"math"
 	"sync"
 	"time"
 
@@ -9,97 +11,363 @@ import (
 	"golang.org/x/sync/semaphore"
 )
 
-type keyValuePair[T any] struct {
-	key           string
-	value         T
+type keyValuePair[K comparable, V any] struct {
+	key           K
+	value         V
+	version       uint64
 	expireRefresh int64
 	expireRotten  int64
+	loadDuration  int64
+	lastAccess    int64
 }
 
-func newKeyValuePair[T any](key string, value T, expireRefresh int64, expireRotten int64) *keyValuePair[T] {
-	return &keyValuePair[T]{
+func newKeyValuePair[K comparable, V any](key K, value V, version uint64, expireRefresh int64, expireRotten int64, loadDuration int64, lastAccess int64) *keyValuePair[K, V] {
+	return &keyValuePair[K, V]{
 		key:           key,
 		value:         value,
+		version:       version,
 		expireRefresh: expireRefresh,
 		expireRotten:  expireRotten,
+		loadDuration:  loadDuration,
+		lastAccess:    lastAccess,
 	}
 }
 
-type KeyValueCache[T any] struct {
+type EntryMeta struct {
+	Version    uint64
+	RefreshAt  time.Time
+	RottenAt   time.Time
+	LastAccess time.Time
+}
+
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+	meta  EntryMeta
+}
+
+// loadFunc loads the value of a key. On a background refresh, refresh is true and old holds the current value.
+type loadFunc[V any] func(ctx context.Context, old V, refresh bool) (V, error)
+
+type keyValueStore[K comparable, V any] struct {
 	clock          clock.Clock
-	dict           *internal.SyncMap[string, *internal.LinkedListNode[*keyValuePair[T]]]
-	keys           *internal.LinkedList[*keyValuePair[T]]
+	dict           *internal.SyncMap[K, *internal.LinkedListNode[*keyValuePair[K, V]]]
+	keys           *internal.LinkedList[*keyValuePair[K, V]]
 	capacity       int
 	mutex          sync.Mutex
 	semaphore      *semaphore.Weighted
 	timeoutRefresh int64
 	timeoutRotten  int64
+	options        *options
+	version        uint64
+	generation     uint64
+	writtenAt      map[K]uint64 // generation of the last write per key, kept while loads are in flight
+	loading        int
+	hotKeys        *HotKeyTracker[K]
 	onRefresh      func() // for test
 }
 
-func NewKeyValueCache[T any](clock clock.Clock, capacity int, timeoutRefresh time.Duration, timeoutRotten time.Duration) *KeyValueCache[T] {
-	return &KeyValueCache[T]{
+func newKeyValueStore[K comparable, V any](clock clock.Clock, capacity int, timeoutRefresh time.Duration, timeoutRotten time.Duration, opts []Option) *keyValueStore[K, V] {
+	c := &keyValueStore[K, V]{
 		clock:          clock,
-		dict:           internal.NewSyncMap[string, *internal.LinkedListNode[*keyValuePair[T]]](),
-		keys:           internal.NewLinkedList[*keyValuePair[T]](),
+		dict:           internal.NewSyncMap[K, *internal.LinkedListNode[*keyValuePair[K, V]]](),
+		keys:           internal.NewLinkedList[*keyValuePair[K, V]](),
 		capacity:       capacity,
 		mutex:          sync.Mutex{},
 		semaphore:      semaphore.NewWeighted(1),
-		timeoutRefresh: int64(timeoutRefresh.Seconds()),
-		timeoutRotten:  int64(timeoutRotten.Seconds()),
+		timeoutRefresh: int64(timeoutRefresh),
+		timeoutRotten:  int64(timeoutRotten),
+		options:        newOptions(opts),
+		writtenAt:      make(map[K]uint64),
+	}
+	if c.options.hotKeys > 0 {
+		c.hotKeys = NewHotKeyTracker[K](c.options.hotKeys)
 	}
+	return c
+}
+
+type KeyValueCache[T any] struct {
+	*keyValueStore[string, T]
+}
+
+func NewKeyValueCache[T any](clock clock.Clock, capacity int, timeoutRefresh time.Duration, timeoutRotten time.Duration, opts ...Option) *KeyValueCache[T] {
+	return &KeyValueCache[T]{newKeyValueStore[string, T](clock, capacity, timeoutRefresh, timeoutRotten, opts)}
 }
 
 func (c *KeyValueCache[T]) Get(key string, getter func() (T, error)) (T, error) {
-	now := c.clock.Now().Unix()
+	return c.GetContext(context.Background(), key, func(context.Context) (T, error) {
+		return getter()
+	})
+}
+
+func (c *KeyValueCache[T]) GetContext(ctx context.Context, key string, getter func(ctx context.Context) (T, error)) (T, error) {
+	return c.get(ctx, key, func(ctx context.Context, _ T, _ bool) (T, error) {
+		return getter(ctx)
+	})
+}
+
+// Lookup returns the cached value and its version without loading or touching the LRU order.
+func (c *KeyValueCache[T]) Lookup(key string) (T, uint64, bool) {
+	return c.lookup(key)
+}
+
+// Invalidate removes the key, and discards any load of it that started before the call.
+func (c *KeyValueCache[T]) Invalidate(key string) {
+	c.invalidate(key)
+}
+
+// CompareAndSwap stores newValue only if the cached version of the key is still oldVersion.
+// An oldVersion of 0 means the key must not be cached; since a key can be loaded and dropped again
+// in the meantime, callers that need to detect such writes should swap from a non-zero version.
+func (c *KeyValueCache[T]) CompareAndSwap(key string, oldVersion uint64, newValue T) bool {
+	return c.compareAndSwap(key, oldVersion, newValue)
+}
+
+// HotKeys returns the most accessed keys, the most accessed first, when WithHotKeyTracking is set.
+func (c *KeyValueCache[T]) HotKeys() []string {
+	return c.hotKeyList()
+}
+
+func (c *KeyValueCache[T]) Evict(fraction float64) int {
+	return c.evict(fraction)
+}
+
+func (c *KeyValueCache[T]) Keys() []string {
+	return c.keyList()
+}
+
+func (c *KeyValueCache[T]) Len() int {
+	return c.len()
+}
+
+// Range calls f for each entry, from the least to the most recently used, until f returns false.
+// It iterates over a snapshot, so f may call back into the cache.
+func (c *KeyValueCache[T]) Range(f func(key string, value T, meta EntryMeta) bool) {
+	c.rangeEntries(f)
+}
+
+func (c *keyValueStore[K, V]) get(ctx context.Context, key K, loader loadFunc[V]) (V, error) {
+	now := c.clock.Now().UnixNano()
+
+	if c.hotKeys != nil {
+		c.hotKeys.Record(key)
+	}
+
+	c.mutex.Lock()
 
 	node, ok := c.dict.Get(key)
 
-	if ok && now < node.Value.expireRefresh {
-		c.mutex.Lock()
+	if ok && now < node.Value.expireRefresh && !c.options.shouldRefreshEarly(now, node.Value.expireRefresh, node.Value.loadDuration) {
 		defer c.mutex.Unlock()
 
 		c.keys.Remove(node)
 		c.keys.AppendLast(node)
+		node.Value.lastAccess = now
 
 		return node.Value.value, nil
 	}
 
 	if ok && now < node.Value.expireRotten {
+		defer c.mutex.Unlock()
+
+		c.keys.Remove(node)
+		c.keys.AppendLast(node)
+		node.Value.lastAccess = now
+
 		isAcquired := c.semaphore.TryAcquire(1)
 		if !isAcquired {
 			return node.Value.value, nil
 		}
-		value := node.Value.value
-		go func() {
-			defer c.semaphore.Release(1)
-			value, err := getter()
-			if err != nil {
-				return
+		generation := c.beginLoad()
+		old := node.Value.value
+		c.options.executor.Go(func() {
+			value, loadDuration, err := c.load(context.Background(), now, func(ctx context.Context) (V, error) {
+				return loader(ctx, old, true)
+			})
+
+			c.mutex.Lock()
+			stored := c.endLoad(key, generation) && err == nil && c.isCurrent(key, node)
+			if stored {
+				c.bumpGeneration(key)
+				c.version++
+				node.Value.value = value
+				node.Value.version = c.version
+				node.Value.expireRefresh = now + c.options.refreshTimeout(c.timeoutRefresh, c.timeoutRotten)
+				node.Value.expireRotten = now + c.timeoutRotten
+				node.Value.loadDuration = loadDuration
 			}
-			node.Value.value = value
-			node.Value.expireRefresh = now + c.timeoutRefresh
-			node.Value.expireRotten = now + c.timeoutRotten
-			if c.onRefresh != nil {
+			c.mutex.Unlock()
+			c.semaphore.Release(1)
+
+			if stored && c.onRefresh != nil {
 				c.onRefresh()
 			}
-		}()
-		c.mutex.Lock()
-		defer c.mutex.Unlock()
+			c.options.notifyLoad(LoadEvent{Key: key, Refresh: true, Stored: stored, Err: err})
+		})
+		return node.Value.value, nil
+	}
+
+	// The load may retry with backoff, so it runs without the lock. Concurrent misses of the key each load,
+	// the first to finish is stored, and the others return what it stored.
+	generation := c.beginLoad()
+	c.mutex.Unlock()
+
+	data, loadDuration, err := c.load(ctx, now, func(ctx context.Context) (V, error) {
+		return loader(ctx, *new(V), false)
+	})
+
+	c.mutex.Lock()
+	stored := c.endLoad(key, generation) && err == nil
+	if stored {
+		c.store(key, data, now, loadDuration)
+	} else if node, ok := c.dict.Get(key); ok && err == nil {
+		data = node.Value.value
+	}
+	c.mutex.Unlock()
+
+	if stored && c.onRefresh != nil {
+		c.onRefresh()
+	}
+	c.options.notifyLoad(LoadEvent{Key: key, Refresh: false, Stored: stored, Err: err})
+	return data, err
+}
+
+// preload loads key without holding the lock, and stores it unless the key was written in the meantime.
+func (c *keyValueStore[K, V]) preload(ctx context.Context, key K, loader loadFunc[V]) error {
+	now := c.clock.Now().UnixNano()
+
+	c.mutex.Lock()
+	generation := c.beginLoad()
+	c.mutex.Unlock()
+
+	data, loadDuration, err := c.load(ctx, now, func(ctx context.Context) (V, error) {
+		return loader(ctx, *new(V), false)
+	})
+
+	c.mutex.Lock()
+	stored := c.endLoad(key, generation) && err == nil
+	if stored {
+		c.store(key, data, now, loadDuration)
+	}
+	c.mutex.Unlock()
+
+	c.options.notifyLoad(LoadEvent{Key: key, Refresh: false, Stored: stored, Err: err})
+	return err
+}
+
+func (c *keyValueStore[K, V]) lookup(key K) (V, uint64, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	node, ok := c.dict.Get(key)
+	if !ok {
+		return *new(V), 0, false
+	}
+	return node.Value.value, node.Value.version, true
+}
+
+func (c *keyValueStore[K, V]) invalidate(key K) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.bumpGeneration(key)
+	if node, ok := c.dict.Get(key); ok {
+		c.dict.Delete(key)
 		c.keys.Remove(node)
-		c.keys.AppendLast(node)
-		return value, nil
 	}
+}
+
+func (c *keyValueStore[K, V]) compareAndSwap(key K, oldVersion uint64, newValue V) bool {
+	now := c.clock.Now().UnixNano()
 
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	data, err := getter()
-	if err != nil {
-		return *new(T), err
+	var version uint64
+	if node, ok := c.dict.Get(key); ok {
+		version = node.Value.version
 	}
+	if version != oldVersion {
+		return false
+	}
+
+	c.store(key, newValue, now, 0)
+	return true
+}
 
-	if ok {
+func (c *keyValueStore[K, V]) hotKeyList() []K {
+	if c.hotKeys == nil {
+		return nil
+	}
+	return c.hotKeys.Top()
+}
+
+func (c *keyValueStore[K, V]) evict(fraction float64) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	n := max(min(int(math.Ceil(float64(c.keys.Len())*fraction)), c.keys.Len()), 0)
+	for i := 0; i < n; i++ {
+		node := c.keys.First()
+		c.dict.Delete(node.Value.key)
+		c.keys.Remove(node)
+	}
+	return n
+}
+
+func (c *keyValueStore[K, V]) keyList() []K {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	keys := make([]K, 0, c.keys.Len())
+	for node := c.keys.First(); node != nil; node = node.Next {
+		keys = append(keys, node.Value.key)
+	}
+	return keys
+}
+
+func (c *keyValueStore[K, V]) len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.keys.Len()
+}
+
+func (c *keyValueStore[K, V]) snapshot() []entry[K, V] {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entries := make([]entry[K, V], 0, c.keys.Len())
+	for node := c.keys.First(); node != nil; node = node.Next {
+		pair := node.Value
+		entries = append(entries, entry[K, V]{
+			key:   pair.key,
+			value: pair.value,
+			meta: EntryMeta{
+				Version:    pair.version,
+				RefreshAt:  time.Unix(0, pair.expireRefresh),
+				RottenAt:   time.Unix(0, pair.expireRotten),
+				LastAccess: time.Unix(0, pair.lastAccess),
+			},
+		})
+	}
+	return entries
+}
+
+func (c *keyValueStore[K, V]) rangeEntries(f func(key K, value V, meta EntryMeta) bool) {
+	for _, e := range c.snapshot() {
+		if !f(e.key, e.value, e.meta) {
+			return
+		}
+	}
+}
+
+// store writes the key, discarding any load of it still in flight, so Warm cannot overwrite a fresher value.
+func (c *keyValueStore[K, V]) store(key K, value V, now int64, loadDuration int64) {
+	c.bumpGeneration(key)
+
+	if node, ok := c.dict.Get(key); ok {
+		c.dict.Delete(key)
 		c.keys.Remove(node)
 	}
 
@@ -109,12 +377,47 @@ func (c *KeyValueCache[T]) Get(key string, getter func() (T, error)) (T, error)
 		c.keys.Remove(node)
 	}
 
-	pair := newKeyValuePair[T](key, data, now+c.timeoutRefresh, now+c.timeoutRotten)
-	node = internal.NewLinkedListNode[*keyValuePair[T]](pair)
+	c.version++
+	pair := newKeyValuePair[K, V](key, value, c.version, now+c.options.refreshTimeout(c.timeoutRefresh, c.timeoutRotten), now+c.timeoutRotten, loadDuration, now)
+	node := internal.NewLinkedListNode[*keyValuePair[K, V]](pair)
 	c.keys.AppendLast(node)
 	c.dict.Set(key, node)
-	if c.onRefresh != nil {
-		c.onRefresh()
+}
+
+func (c *keyValueStore[K, V]) isCurrent(key K, node *internal.LinkedListNode[*keyValuePair[K, V]]) bool {
+	current, ok := c.dict.Get(key)
+	return ok && current == node
+}
+
+func (c *keyValueStore[K, V]) beginLoad() uint64 {
+	c.loading++
+	return c.generation
+}
+
+// endLoad reports whether the result of a load started at generation may still be stored.
+func (c *keyValueStore[K, V]) endLoad(key K, generation uint64) bool {
+	c.loading--
+	ok := c.writtenAt[key] <= generation
+	if c.loading == 0 {
+		clear(c.writtenAt)
+	}
+	return ok
+}
+
+func (c *keyValueStore[K, V]) bumpGeneration(key K) {
+	c.generation++
+	if c.loading > 0 {
+		c.writtenAt[key] = c.generation
+	}
+}
+
+func (c *keyValueStore[K, V]) load(ctx context.Context, start int64, getter func(ctx context.Context) (V, error)) (V, int64, error) {
+	value, err := retryLoad(ctx, c.clock, c.options, getter)
+	if err != nil {
+		return *new(V), 0, err
+	}
+	if !c.options.measureLoad() {
+		return value, 0, nil
 	}
-	return data, nil
+	return value, c.clock.Now().UnixNano() - start, nil
 }
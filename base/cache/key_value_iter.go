@@ -0,0 +1,25 @@
+//go:build go1.23
+
+package cache
+
+import "iter"
+
+// All returns an iterator over a snapshot of the entries, from the least to the most recently used.
+func (c *KeyValueCache[T]) All() iter.Seq2[string, T] {
+	return c.all()
+}
+
+// All returns an iterator over a snapshot of the entries, from the least to the most recently used.
+func (c *LoadingCache[K, V]) All() iter.Seq2[K, V] {
+	return c.all()
+}
+
+func (c *keyValueStore[K, V]) all() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, e := range c.snapshot() {
+			if !yield(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+//go:build go1.23
+
+package cache
+
+import (
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Iter Test", func() {
+	c := clock.NewFake(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+
+	vc := NewKeyValueCache[int](c, 2, 5*time.Second, 30*time.Second)
+
+	It("iterates over a snapshot", func() {
+		_, err := vc.Get("a", func() (int, error) { return 1, nil })
+		Expect(err).NotTo(HaveOccurred())
+		_, err = vc.Get("b", func() (int, error) { return 2, nil })
+		Expect(err).NotTo(HaveOccurred())
+
+		result := map[string]int{}
+		for k, v := range vc.All() {
+			vc.Invalidate(k)
+			result[k] = v
+		}
+		Expect(result).To(Equal(map[string]int{"a": 1, "b": 2}))
+		Expect(vc.Len()).To(Equal(0))
+	})
+})
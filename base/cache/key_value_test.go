@@ -3,7 +3,6 @@ package cache
 import (
 	"errors"
 	"sync"
-	"testing"
 	"time"
 
 	"github.com/omnius-labs/core-go/base/clock"
@@ -11,21 +10,8 @@ import (
 	. "github.com/onsi/gomega"
 )
 
-func TestKeyValueCache(t *testing.T) {
-	RegisterFailHandler(Fail)
-	RunSpecs(t, "KeyValueCache Spec")
-}
-
 var _ = Describe("Success Test", func() {
-	c := clock.NewMock(
-		[]time.Time{
-			time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 10, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 10, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 60, 0, time.UTC),
-		},
-	)
+	c := clock.NewFake(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
 	fr := 0
 	f := func() (int, error) {
 		fr++
@@ -52,6 +38,7 @@ var _ = Describe("Success Test", func() {
 	})
 
 	It("use cache, second fetch", func() {
+		c.Advance(10 * time.Second)
 		wg.Add(1)
 		ret, err := vc.Get("a", f)
 		Expect(ret).To(Equal(1))
@@ -65,6 +52,7 @@ var _ = Describe("Success Test", func() {
 	})
 
 	It("third fetch", func() {
+		c.Advance(50 * time.Second)
 		wg.Add(1)
 		ret, err := vc.Get("a", f)
 		Expect(ret).To(Equal(3))
@@ -75,19 +63,7 @@ var _ = Describe("Success Test", func() {
 })
 
 var _ = Describe("Success Test 2", func() {
-	c := clock.NewMock(
-		[]time.Time{
-			time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC),
-		},
-	)
+	c := clock.NewFake(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
 	fr := 0
 	f := func() (int, error) {
 		fr++
@@ -150,13 +126,7 @@ var _ = Describe("Success Test 2", func() {
 })
 
 var _ = Describe("Error Test", func() {
-	c := clock.NewMock(
-		[]time.Time{
-			time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC),
-		},
-	)
+	c := clock.NewFake(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
 	fr := 0
 	f := func() (int, error) {
 		fr++
@@ -183,3 +153,137 @@ var _ = Describe("Error Test", func() {
 		Expect(fr).To(Equal(2))
 	})
 })
+
+var _ = Describe("Version Test", func() {
+	c := clock.NewFake(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+	fr := 0
+	release := make(chan struct{})
+	f := func() (int, error) {
+		fr++
+		if fr == 2 {
+			<-release
+		}
+		return fr, nil
+	}
+
+	vc := NewKeyValueCache[int](c, 2, 5*time.Second, 30*time.Second)
+	isRefreshDone := func() bool {
+		if !vc.semaphore.TryAcquire(1) {
+			return false
+		}
+		vc.semaphore.Release(1)
+		return true
+	}
+
+	It("first fetch", func() {
+		ret, err := vc.Get("a", f)
+		Expect(ret).To(Equal(1))
+		Expect(err).NotTo(HaveOccurred())
+
+		ret, version, ok := vc.Lookup("a")
+		Expect(ret).To(Equal(1))
+		Expect(version).To(Equal(uint64(1)))
+		Expect(ok).To(BeTrue())
+	})
+
+	It("refresh started before invalidation is discarded", func() {
+		c.Advance(10 * time.Second)
+		ret, err := vc.Get("a", f)
+		Expect(ret).To(Equal(1))
+		Expect(err).NotTo(HaveOccurred())
+
+		vc.Invalidate("a")
+		close(release)
+		Eventually(isRefreshDone).Should(BeTrue())
+
+		_, _, ok := vc.Lookup("a")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("load after invalidation is stored", func() {
+		ret, err := vc.Get("a", f)
+		Expect(ret).To(Equal(3))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("compare and swap", func() {
+		_, version, ok := vc.Lookup("a")
+		Expect(ok).To(BeTrue())
+
+		Expect(vc.CompareAndSwap("a", version, 100)).To(BeTrue())
+		Expect(vc.CompareAndSwap("a", version, 200)).To(BeFalse())
+
+		ret, newVersion, ok := vc.Lookup("a")
+		Expect(ret).To(Equal(100))
+		Expect(newVersion).To(BeNumerically(">", version))
+		Expect(ok).To(BeTrue())
+	})
+
+	It("compare and swap absent key", func() {
+		Expect(vc.CompareAndSwap("b", 0, 5)).To(BeTrue())
+
+		ret, _, ok := vc.Lookup("b")
+		Expect(ret).To(Equal(5))
+		Expect(ok).To(BeTrue())
+	})
+})
+
+var _ = Describe("Range Test", func() {
+	c := clock.NewFake(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+	f := func(v int) func() (int, error) {
+		return func() (int, error) {
+			return v, nil
+		}
+	}
+
+	vc := NewKeyValueCache[int](c, 2, 5*time.Second, 30*time.Second)
+
+	It("empty", func() {
+		Expect(vc.Len()).To(Equal(0))
+		Expect(vc.Keys()).To(BeEmpty())
+	})
+
+	It("lists keys from the least recently used", func() {
+		_, err := vc.Get("a", f(1))
+		Expect(err).NotTo(HaveOccurred())
+		c.Advance(time.Second)
+		_, err = vc.Get("b", f(2))
+		Expect(err).NotTo(HaveOccurred())
+		c.Advance(time.Second)
+		_, err = vc.Get("a", f(3))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(vc.Len()).To(Equal(2))
+		Expect(vc.Keys()).To(Equal([]string{"b", "a"}))
+	})
+
+	It("ranges with meta", func() {
+		var keys []string
+		var values []int
+		var metas []EntryMeta
+		vc.Range(func(key string, value int, meta EntryMeta) bool {
+			Expect(vc.Len()).To(Equal(2))
+			keys = append(keys, key)
+			values = append(values, value)
+			metas = append(metas, meta)
+			return true
+		})
+		Expect(keys).To(Equal([]string{"b", "a"}))
+		Expect(values).To(Equal([]int{2, 1}))
+
+		Expect(metas[0].RefreshAt).To(BeTemporally("==", time.Date(2000, time.January, 1, 1, 0, 6, 0, time.UTC)))
+		Expect(metas[0].RottenAt).To(BeTemporally("==", time.Date(2000, time.January, 1, 1, 0, 31, 0, time.UTC)))
+		Expect(metas[0].LastAccess).To(BeTemporally("==", time.Date(2000, time.January, 1, 1, 0, 1, 0, time.UTC)))
+		Expect(metas[1].RefreshAt).To(BeTemporally("==", time.Date(2000, time.January, 1, 1, 0, 5, 0, time.UTC)))
+		Expect(metas[1].LastAccess).To(BeTemporally("==", time.Date(2000, time.January, 1, 1, 0, 2, 0, time.UTC)))
+	})
+
+	It("stops when f returns false", func() {
+		n := 0
+		vc.Range(func(key string, value int, meta EntryMeta) bool {
+			n++
+			return false
+		})
+		Expect(n).To(Equal(1))
+	})
+})
@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+)
+
+type Loader[K comparable, V any] interface {
+	Load(ctx context.Context, key K) (V, error)
+}
+
+// Reloader is implemented by loaders that can refresh a value more cheaply when they know the current one,
+// e.g. by a conditional fetch with an ETag or an updated_at column.
+type Reloader[K comparable, V any] interface {
+	Reload(ctx context.Context, key K, oldValue V) (V, error)
+}
+
+var _ Loader[string, any] = LoaderFunc[string, any](nil)
+
+type LoaderFunc[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+func (f LoaderFunc[K, V]) Load(ctx context.Context, key K) (V, error) {
+	return f(ctx, key)
+}
+
+// LoadingCache is a KeyValueCache bound to a single Loader. Background refreshes use Reload when the loader implements Reloader.
+type LoadingCache[K comparable, V any] struct {
+	*keyValueStore[K, V]
+	loadKey func(ctx context.Context, key K, old V, refresh bool) (V, error)
+}
+
+func NewLoadingCache[K comparable, V any](clock clock.Clock, capacity int, timeoutRefresh time.Duration, timeoutRotten time.Duration, loader Loader[K, V], opts ...Option) *LoadingCache[K, V] {
+	load := func(ctx context.Context, key K, _ V, _ bool) (V, error) {
+		return loader.Load(ctx, key)
+	}
+	if reloader, ok := loader.(Reloader[K, V]); ok {
+		load = func(ctx context.Context, key K, old V, refresh bool) (V, error) {
+			if refresh {
+				return reloader.Reload(ctx, key, old)
+			}
+			return loader.Load(ctx, key)
+		}
+	}
+
+	return &LoadingCache[K, V]{
+		keyValueStore: newKeyValueStore[K, V](clock, capacity, timeoutRefresh, timeoutRotten, opts),
+		loadKey:       load,
+	}
+}
+
+func (c *LoadingCache[K, V]) Get(ctx context.Context, key K) (V, error) {
+	return c.get(ctx, key, func(ctx context.Context, old V, refresh bool) (V, error) {
+		return c.loadKey(ctx, key, old, refresh)
+	})
+}
+
+// Lookup returns the cached value and its version without loading or touching the LRU order.
+func (c *LoadingCache[K, V]) Lookup(key K) (V, uint64, bool) {
+	return c.lookup(key)
+}
+
+// Invalidate removes the key, and discards any load of it that started before the call.
+func (c *LoadingCache[K, V]) Invalidate(key K) {
+	c.invalidate(key)
+}
+
+// CompareAndSwap stores newValue only if the cached version of the key is still oldVersion.
+func (c *LoadingCache[K, V]) CompareAndSwap(key K, oldVersion uint64, newValue V) bool {
+	return c.compareAndSwap(key, oldVersion, newValue)
+}
+
+// HotKeys returns the most accessed keys, the most accessed first, when WithHotKeyTracking is set.
+// The list can be handed to Warm of the next instance.
+func (c *LoadingCache[K, V]) HotKeys() []K {
+	return c.hotKeyList()
+}
+
+func (c *LoadingCache[K, V]) Evict(fraction float64) int {
+	return c.evict(fraction)
+}
+
+func (c *LoadingCache[K, V]) Keys() []K {
+	return c.keyList()
+}
+
+func (c *LoadingCache[K, V]) Len() int {
+	return c.len()
+}
+
+// Range calls f for each entry, from the least to the most recently used, until f returns false.
+// It iterates over a snapshot, so f may call back into the cache.
+func (c *LoadingCache[K, V]) Range(f func(key K, value V, meta EntryMeta) bool) {
+	c.rangeEntries(f)
+}
@@ -0,0 +1,131 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/cache"
+	"github.com/omnius-labs/core-go/base/cache/cachetest"
+	"github.com/omnius-labs/core-go/base/clock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type userLoader struct {
+	mutex   sync.Mutex
+	loads   []int
+	reloads []string
+}
+
+func (l *userLoader) Load(ctx context.Context, id int) (string, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.loads = append(l.loads, id)
+	return fmt.Sprintf("user-%d@%d", id, len(l.loads)), nil
+}
+
+func (l *userLoader) Reload(ctx context.Context, id int, old string) (string, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.reloads = append(l.reloads, old)
+	return fmt.Sprintf("user-%d@reload%d", id, len(l.reloads)), nil
+}
+
+var _ = Describe("LoadingCache Test", func() {
+	It("loads and reloads through the loader", func() {
+		c := cachetest.NewClock(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+		recorder := cachetest.NewLoadRecorder()
+		loader := &userLoader{}
+		lc := cache.NewLoadingCache[int, string](c, 2, 5*time.Second, 30*time.Second, loader, recorder.Option())
+		ctx := context.Background()
+
+		ret, err := lc.Get(ctx, 1)
+		Expect(ret).To(Equal("user-1@1"))
+		Expect(err).NotTo(HaveOccurred())
+
+		ret, err = lc.Get(ctx, 1)
+		Expect(ret).To(Equal("user-1@1"))
+		Expect(err).NotTo(HaveOccurred())
+
+		c.Advance(10 * time.Second)
+		ret, err = lc.Get(ctx, 1)
+		Expect(ret).To(Equal("user-1@1"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.AwaitRefreshes(1)).To(Succeed())
+
+		ret, err = lc.Get(ctx, 1)
+		Expect(ret).To(Equal("user-1@reload1"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loader.loads).To(Equal([]int{1}))
+		Expect(loader.reloads).To(Equal([]string{"user-1@1"}))
+
+		c.Advance(60 * time.Second)
+		ret, err = lc.Get(ctx, 1)
+		Expect(ret).To(Equal("user-1@2"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("refreshes with Load when the loader has no Reload", func() {
+		c := cachetest.NewClock(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+		recorder := cachetest.NewLoadRecorder()
+		calls := 0
+		loader := cache.LoaderFunc[string, int](func(ctx context.Context, key string) (int, error) {
+			calls++
+			return calls, nil
+		})
+		lc := cache.NewLoadingCache[string, int](c, 2, 5*time.Second, 30*time.Second, loader, recorder.Option())
+		ctx := context.Background()
+
+		ret, err := lc.Get(ctx, "a")
+		Expect(ret).To(Equal(1))
+		Expect(err).NotTo(HaveOccurred())
+
+		c.Advance(10 * time.Second)
+		_, err = lc.Get(ctx, "a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.AwaitRefreshes(1)).To(Succeed())
+
+		ret, err = lc.Get(ctx, "a")
+		Expect(ret).To(Equal(2))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("refreshes at the time the value went stale", func() {
+		start := time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC)
+		base := cachetest.NewClock(start)
+		c := clock.NewRecording(base, false)
+		c.LogOnFailure(GinkgoT())
+		recorder := cachetest.NewLoadRecorder()
+		lc := cache.NewLoadingCache[int, string](c, 2, 5*time.Second, 30*time.Second, &userLoader{}, recorder.Option())
+
+		_, err := lc.Get(context.Background(), 1)
+		Expect(err).NotTo(HaveOccurred())
+
+		base.Advance(10 * time.Second)
+		c.Reset()
+		_, err = lc.Get(context.Background(), 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(recorder.AwaitRefreshes(1)).To(Succeed())
+
+		Expect(c.NowCalls()).NotTo(BeEmpty())
+		Expect(c.NowCalls()).To(HaveEach(BeTemporally("==", start.Add(10*time.Second))))
+	})
+
+	It("returns load error", func() {
+		c := cachetest.NewClock(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+		loader := cache.LoaderFunc[string, int](func(ctx context.Context, key string) (int, error) {
+			return 0, errors.New("error")
+		})
+		lc := cache.NewLoadingCache[string, int](c, 2, 5*time.Second, 30*time.Second, loader)
+
+		ret, err := lc.Get(context.Background(), "a")
+		Expect(ret).To(Equal(0))
+		Expect(err).To(HaveOccurred())
+
+		_, _, ok := lc.Lookup("a")
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+	"sync"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+)
+
+type Evictable interface {
+	// Evict removes the given fraction of entries, the least recently used first, and returns how many were removed.
+	Evict(fraction float64) int
+}
+
+type MemoryStats struct {
+	HeapLive uint64
+	Limit    uint64
+	GCCycles uint64
+}
+
+type MemoryReader func() MemoryStats
+
+// NewRuntimeMemoryReader reads the live heap from runtime/metrics. The limit is softLimit,
+// or the limit set by debug.SetMemoryLimit when softLimit is 0.
+func NewRuntimeMemoryReader(softLimit uint64) MemoryReader {
+	return func() MemoryStats {
+		samples := []metrics.Sample{
+			{Name: "/gc/heap/live:bytes"},
+			{Name: "/gc/cycles/total:gc-cycles"},
+		}
+		metrics.Read(samples)
+
+		limit := softLimit
+		if limit == 0 {
+			limit = uint64(debug.SetMemoryLimit(-1))
+		}
+
+		return MemoryStats{
+			HeapLive: sampleUint64(samples[0]),
+			Limit:    limit,
+			GCCycles: sampleUint64(samples[1]),
+		}
+	}
+}
+
+func sampleUint64(s metrics.Sample) uint64 {
+	if s.Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return s.Value.Uint64()
+}
+
+// MemoryGovernor evicts from all registered caches, in proportion to their size,
+// when the live heap passes threshold of the memory limit.
+type MemoryGovernor struct {
+	clock     clock.Clock
+	reader    MemoryReader
+	interval  time.Duration
+	threshold float64
+	mutex     sync.Mutex
+	caches    []Evictable
+	evictedAt uint64
+	evicted   bool
+}
+
+func NewMemoryGovernor(clock clock.Clock, reader MemoryReader, interval time.Duration, threshold float64) *MemoryGovernor {
+	return &MemoryGovernor{
+		clock:     clock,
+		reader:    reader,
+		interval:  interval,
+		threshold: threshold,
+	}
+}
+
+func (g *MemoryGovernor) Register(c Evictable) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.caches = append(g.caches, c)
+}
+
+func (g *MemoryGovernor) Unregister(c Evictable) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	for i, v := range g.caches {
+		if v == c {
+			g.caches = append(g.caches[:i], g.caches[i+1:]...)
+			return
+		}
+	}
+}
+
+// Run calls Check every interval until ctx is done.
+func (g *MemoryGovernor) Run(ctx context.Context) error {
+	for {
+		if err := g.clock.Sleep(ctx, g.interval); err != nil {
+			return err
+		}
+		g.Check()
+	}
+}
+
+// Check evicts once if the memory is under pressure, and returns how many entries were removed.
+// Since the live heap is only updated by a GC, it does nothing until a GC has run after its last eviction.
+func (g *MemoryGovernor) Check() int {
+	stats := g.reader()
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if stats.Limit == 0 || stats.Limit == math.MaxInt64 {
+		return 0
+	}
+	if g.evicted && stats.GCCycles == g.evictedAt {
+		return 0
+	}
+
+	target := g.threshold * float64(stats.Limit)
+	if float64(stats.HeapLive) < target {
+		return 0
+	}
+	fraction := (float64(stats.HeapLive) - target) / float64(stats.HeapLive)
+	fraction = math.Min(math.Max(fraction, 0.01), 1)
+
+	n := 0
+	for _, c := range g.caches {
+		n += c.Evict(fraction)
+	}
+	g.evicted = true
+	g.evictedAt = stats.GCCycles
+	return n
+}
@@ -0,0 +1,117 @@
+package cache
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MemoryGovernor Test", func() {
+	var stats MemoryStats
+	reader := func() MemoryStats {
+		return stats
+	}
+
+	newCache := func(n int) *KeyValueCache[int] {
+		c := clock.NewFake(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+		vc := NewKeyValueCache[int](c, n, 5*time.Second, 30*time.Second)
+		for i := 0; i < n; i++ {
+			_, err := vc.Get(strconv.Itoa(i), func() (int, error) { return i, nil })
+			Expect(err).NotTo(HaveOccurred())
+		}
+		return vc
+	}
+
+	It("does nothing below threshold", func() {
+		stats = MemoryStats{HeapLive: 80, Limit: 100, GCCycles: 1}
+		g := NewMemoryGovernor(clock.New(), reader, time.Second, 0.9)
+		vc := newCache(10)
+		g.Register(vc)
+
+		Expect(g.Check()).To(Equal(0))
+		Expect(vc.Len()).To(Equal(10))
+	})
+
+	It("evicts proportionally under pressure", func() {
+		stats = MemoryStats{HeapLive: 100, Limit: 100, GCCycles: 1}
+		g := NewMemoryGovernor(clock.New(), reader, time.Second, 0.8)
+		vc1 := newCache(10)
+		vc2 := newCache(20)
+		g.Register(vc1)
+		g.Register(vc2)
+
+		Expect(g.Check()).To(Equal(6))
+		Expect(vc1.Len()).To(Equal(8))
+		Expect(vc2.Len()).To(Equal(16))
+		Expect(vc1.Keys()).To(Equal([]string{"2", "3", "4", "5", "6", "7", "8", "9"}))
+	})
+
+	It("waits for a GC before evicting again", func() {
+		stats = MemoryStats{HeapLive: 100, Limit: 100, GCCycles: 1}
+		g := NewMemoryGovernor(clock.New(), reader, time.Second, 0.5)
+		vc := newCache(10)
+		g.Register(vc)
+
+		Expect(g.Check()).To(Equal(5))
+		Expect(g.Check()).To(Equal(0))
+
+		stats = MemoryStats{HeapLive: 60, Limit: 100, GCCycles: 2}
+		Expect(g.Check()).To(Equal(1))
+		Expect(vc.Len()).To(Equal(4))
+	})
+
+	It("ignores unlimited memory", func() {
+		stats = MemoryStats{HeapLive: 100, Limit: 0, GCCycles: 1}
+		g := NewMemoryGovernor(clock.New(), reader, time.Second, 0.5)
+		vc := newCache(10)
+		g.Register(vc)
+
+		Expect(g.Check()).To(Equal(0))
+	})
+
+	It("unregisters", func() {
+		stats = MemoryStats{HeapLive: 100, Limit: 100, GCCycles: 1}
+		g := NewMemoryGovernor(clock.New(), reader, time.Second, 0.5)
+		vc := newCache(10)
+		g.Register(vc)
+		g.Unregister(vc)
+
+		Expect(g.Check()).To(Equal(0))
+		Expect(vc.Len()).To(Equal(10))
+	})
+
+	It("runs until canceled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		c := clock.NewFake(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+		checks := 0
+		g := NewMemoryGovernor(c, func() MemoryStats {
+			checks++
+			if checks == 3 {
+				cancel()
+			}
+			return MemoryStats{}
+		}, time.Second, 0.5)
+
+		done := make(chan error)
+		go func() { done <- g.Run(ctx) }()
+		for i := 0; i < 3; i++ {
+			c.BlockUntil(1)
+			c.Advance(time.Second)
+		}
+
+		Eventually(done).Should(Receive(MatchError(context.Canceled)))
+		Expect(checks).To(Equal(3))
+	})
+
+	It("reads runtime metrics", func() {
+		runtime.GC() // the live heap is only known after a GC
+		s := NewRuntimeMemoryReader(1 << 30)()
+		Expect(s.HeapLive).To(BeNumerically(">", 0))
+		Expect(s.Limit).To(Equal(uint64(1 << 30)))
+	})
+})
@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"github.com/omnius-labs/core-go/base/clock"
+)
+
+type Option func(*options)
+
+type options struct {
+	jitter   float64
+	beta     float64
+	rand     clock.Rand
+	executor clock.Executor
+	retry    *RetryPolicy
+	onLoad   func(event LoadEvent)
+
+	hotKeys      int
+	warmProgress func(done int, total int)
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		rand:     defaultRand{},
+		executor: goExecutor{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithJitter spreads the refresh timeout of each stored value by ±percent,
+// so that values loaded at the same time do not go stale at the same time.
+func WithJitter(percent float64) Option {
+	return func(o *options) {
+		o.jitter = math.Max(0, math.Min(percent, 100)) / 100
+	}
+}
+
+// WithEarlyRefresh enables XFetch-style probabilistic early refresh.
+// The larger beta is, the earlier a refresh is likely to start; 1.0 is a good default.
+func WithEarlyRefresh(beta float64) Option {
+	return func(o *options) {
+		o.beta = math.Max(0, beta)
+	}
+}
+
+func WithRand(r clock.Rand) Option {
+	return func(o *options) {
+		o.rand = r
+	}
+}
+
+// WithExecutor runs background refreshes on e. The default starts a goroutine for each one.
+func WithExecutor(e clock.Executor) Option {
+	return func(o *options) {
+		o.executor = e
+	}
+}
+
+// LoadEvent describes a finished load. Refresh is false for loads done on a cache miss,
+// and Stored is false when the result was discarded because of an error or a concurrent write.
+type LoadEvent struct {
+	Key     any
+	Refresh bool
+	Stored  bool
+	Err     error
+}
+
+// WithLoadHook registers a function that is called after every load, outside of the cache lock.
+func WithLoadHook(f func(event LoadEvent)) Option {
+	return func(o *options) {
+		o.onLoad = f
+	}
+}
+
+// WithHotKeyTracking makes the cache track its n most accessed keys, see HotKeys.
+func WithHotKeyTracking(n int) Option {
+	return func(o *options) {
+		o.hotKeys = n
+	}
+}
+
+// WithWarmProgress registers a function that is called each time Warm finishes a key.
+func WithWarmProgress(f func(done int, total int)) Option {
+	return func(o *options) {
+		o.warmProgress = f
+	}
+}
+
+func (o *options) notifyLoad(event LoadEvent) {
+	if o.onLoad != nil {
+		o.onLoad(event)
+	}
+}
+
+func (o *options) refreshTimeout(timeoutRefresh int64, timeoutRotten int64) int64 {
+	if o.jitter == 0 {
+		return timeoutRefresh
+	}
+	factor := 1 + o.jitter*(2*o.rand.Float64()-1)
+	timeout := int64(float64(timeoutRefresh) * factor)
+	return max(0, min(timeout, timeoutRotten))
+}
+
+// shouldRefreshEarly reports whether a value that is still fresh should be refreshed,
+// weighted by delta, the time the last load took.
+func (o *options) shouldRefreshEarly(now int64, expireRefresh int64, delta int64) bool {
+	if o.beta == 0 || delta <= 0 {
+		return false
+	}
+	gap := float64(delta) * o.beta * -math.Log(1-o.rand.Float64())
+	return float64(now)+gap >= float64(expireRefresh)
+}
+
+func (o *options) measureLoad() bool {
+	return o.beta > 0
+}
+
+type defaultRand struct{}
+
+func (defaultRand) Float64() float64 {
+	return rand.Float64()
+}
+
+type goExecutor struct{}
+
+func (goExecutor) Go(f func()) {
+	go f()
+}
@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	"github.com/omnius-labs/core-go/base/sim"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type randMock struct {
+	data []float64
+}
+
+func (r *randMock) Float64() float64 {
+	if len(r.data) == 0 {
+		panic("rand mock data is empty")
+	}
+
+	v := r.data[0]
+	r.data = r.data[1:]
+	return v
+}
+
+var _ = Describe("Jitter Test", func() {
+	It("spreads refresh timeout", func() {
+		o := newOptions([]Option{WithJitter(50), WithRand(&randMock{data: []float64{0, 0.5, 0.75}})})
+		timeout := int64(10 * time.Second)
+		rotten := int64(60 * time.Second)
+		Expect(o.refreshTimeout(timeout, rotten)).To(Equal(int64(5 * time.Second)))
+		Expect(o.refreshTimeout(timeout, rotten)).To(Equal(int64(10 * time.Second)))
+		Expect(o.refreshTimeout(timeout, rotten)).To(Equal(int64(12500 * time.Millisecond)))
+	})
+
+	It("never exceeds rotten timeout", func() {
+		o := newOptions([]Option{WithJitter(100), WithRand(&randMock{data: []float64{0.99}})})
+		Expect(o.refreshTimeout(int64(10*time.Second), int64(15*time.Second))).To(Equal(int64(15 * time.Second)))
+	})
+
+	It("disabled by default", func() {
+		o := newOptions(nil)
+		Expect(o.refreshTimeout(int64(10*time.Second), int64(60*time.Second))).To(Equal(int64(10 * time.Second)))
+	})
+})
+
+var _ = Describe("Early Refresh Test", func() {
+	expire := int64(100 * time.Second)
+	delta := int64(2 * time.Second)
+
+	It("rarely refreshes far from expiry", func() {
+		o := newOptions([]Option{WithEarlyRefresh(1), WithRand(&randMock{data: []float64{0.5}})})
+		Expect(o.shouldRefreshEarly(int64(90*time.Second), expire, delta)).To(BeFalse())
+	})
+
+	It("refreshes near expiry", func() {
+		o := newOptions([]Option{WithEarlyRefresh(1), WithRand(&randMock{data: []float64{0.5}})})
+		Expect(o.shouldRefreshEarly(int64(99*time.Second), expire, delta)).To(BeTrue())
+	})
+
+	It("weighted by load duration", func() {
+		o := newOptions([]Option{WithEarlyRefresh(1), WithRand(&randMock{data: []float64{0.5, 0.5}})})
+		Expect(o.shouldRefreshEarly(int64(95*time.Second), expire, delta)).To(BeFalse())
+		Expect(o.shouldRefreshEarly(int64(95*time.Second), expire, 4*delta)).To(BeTrue())
+	})
+
+	It("disabled by default", func() {
+		o := newOptions(nil)
+		Expect(o.shouldRefreshEarly(int64(99*time.Second), expire, delta)).To(BeFalse())
+	})
+})
+
+var _ = Describe("KeyValueCache Jitter Test", func() {
+	c := clock.NewFake(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+	fr := 0
+	f := func() (int, error) {
+		fr++
+		return fr, nil
+	}
+
+	r := &randMock{data: []float64{0, 0.99, 0.5}}
+	vc := NewKeyValueCache[int](c, 2, 10*time.Second, 30*time.Second, WithJitter(50), WithRand(r))
+	wg := &sync.WaitGroup{}
+	vc.onRefresh = func() {
+		wg.Done()
+	}
+
+	It("first fetch", func() {
+		wg.Add(2)
+		ret, err := vc.Get("a", f)
+		Expect(ret).To(Equal(1))
+		Expect(err).NotTo(HaveOccurred())
+		ret, err = vc.Get("b", f)
+		Expect(ret).To(Equal(2))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("only the shorter one refreshes", func() {
+		c.Advance(6 * time.Second)
+		wg.Add(1)
+		ret, err := vc.Get("a", f)
+		Expect(ret).To(Equal(1))
+		Expect(err).NotTo(HaveOccurred())
+
+		wg.Wait()
+
+		ret, err = vc.Get("b", f)
+		Expect(ret).To(Equal(2))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fr).To(Equal(3))
+	})
+})
+
+var _ = Describe("Executor Test", func() {
+	It("refreshes in the background only when stepped", func() {
+		s := sim.New(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+		fr := 0
+		f := func() (int, error) {
+			fr++
+			return fr, nil
+		}
+		vc := NewKeyValueCache[int](s.Clock(), 2, 5*time.Second, 30*time.Second, WithExecutor(s))
+		ret, err := vc.Get("a", f)
+		Expect(ret).To(Equal(1))
+		Expect(err).NotTo(HaveOccurred())
+
+		s.Advance(10 * time.Second)
+		ret, err = vc.Get("a", f)
+		Expect(ret).To(Equal(1))
+		Expect(err).NotTo(HaveOccurred())
+		ret, err = vc.Get("a", f)
+		Expect(ret).To(Equal(1))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Pending()).To(Equal(1))
+		Expect(fr).To(Equal(1))
+
+		Expect(s.Step()).To(BeTrue())
+		ret, err = vc.Get("a", f)
+		Expect(ret).To(Equal(2))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.CheckLeaks()).To(Succeed())
+	})
+})
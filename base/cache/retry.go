@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	"github.com/omnius-labs/core-go/base/retry"
+)
+
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	Jitter         float64
+	Retryable      func(err error) bool
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+func WithRetry(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = &policy
+	}
+}
+
+func (p *RetryPolicy) exponential() *retry.Exponential {
+	return &retry.Exponential{
+		Initial:    p.InitialBackoff,
+		Max:        p.MaxBackoff,
+		Multiplier: p.Multiplier,
+		Jitter:     p.Jitter,
+	}
+}
+
+func retryLoad[T any](ctx context.Context, clock clock.Clock, o *options, getter func(ctx context.Context) (T, error)) (T, error) {
+	if o.retry == nil {
+		return getter(ctx)
+	}
+
+	r := retry.New(clock, o.retry.exponential(),
+		retry.WithMaxAttempts(max(o.retry.MaxAttempts, 1)),
+		retry.WithClassifier(o.retry.Retryable),
+		retry.WithRand(o.rand),
+	)
+	return retry.DoValue(ctx, r, getter)
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Retry Test", func() {
+	policy := RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     300 * time.Millisecond,
+		Multiplier:     2,
+	}
+	errTransient := errors.New("transient")
+	errFatal := errors.New("fatal")
+
+	newClock := func() *clock.FakeClock {
+		return clock.NewFake(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC), clock.WithAutoAdvance())
+	}
+
+	It("retries until success", func() {
+		c := newClock()
+		vc := NewKeyValueCache[int](c, 2, 5*time.Second, 30*time.Second, WithRetry(policy))
+		fr := 0
+		ret, err := vc.Get("a", func() (int, error) {
+			fr++
+			if fr < 3 {
+				return 0, errTransient
+			}
+			return fr, nil
+		})
+		Expect(ret).To(Equal(3))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Slept()).To(Equal([]time.Duration{100 * time.Millisecond, 200 * time.Millisecond}))
+	})
+
+	It("gives up after max attempts", func() {
+		c := newClock()
+		vc := NewValueCache[int](c, 5*time.Second, 30*time.Second, WithRetry(policy))
+		fr := 0
+		ret, err := vc.Get(func() (int, error) {
+			fr++
+			return 0, errTransient
+		})
+		Expect(ret).To(Equal(0))
+		Expect(err).To(MatchError(errTransient))
+		Expect(fr).To(Equal(4))
+		Expect(c.Slept()).To(Equal([]time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond}))
+	})
+
+	It("does not retry non-retryable errors", func() {
+		c := newClock()
+		p := policy
+		p.Retryable = func(err error) bool {
+			return !errors.Is(err, errFatal)
+		}
+		vc := NewKeyValueCache[int](c, 2, 5*time.Second, 30*time.Second, WithRetry(p))
+		fr := 0
+		_, err := vc.Get("a", func() (int, error) {
+			fr++
+			return 0, errFatal
+		})
+		Expect(err).To(MatchError(errFatal))
+		Expect(fr).To(Equal(1))
+		Expect(c.Slept()).To(BeEmpty())
+	})
+
+	It("respects context cancellation", func() {
+		c := newClock()
+		vc := NewKeyValueCache[int](c, 2, 5*time.Second, 30*time.Second, WithRetry(policy))
+		ctx, cancel := context.WithCancel(context.Background())
+		fr := 0
+		_, err := vc.GetContext(ctx, "a", func(context.Context) (int, error) {
+			fr++
+			cancel()
+			return 0, errTransient
+		})
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(fr).To(Equal(1))
+	})
+
+	It("applies jitter", func() {
+		c := newClock()
+		p := policy
+		p.Jitter = 0.5
+		vc := NewKeyValueCache[int](c, 2, 5*time.Second, 30*time.Second, WithRetry(p), WithRand(&randMock{data: []float64{0, 0.99}}))
+		fr := 0
+		_, err := vc.Get("a", func() (int, error) {
+			fr++
+			if fr < 3 {
+				return 0, errTransient
+			}
+			return fr, nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Slept()).To(HaveLen(2))
+		Expect(c.Slept()[0]).To(Equal(50 * time.Millisecond))
+		Expect(c.Slept()[1]).To(BeNumerically("~", 298*time.Millisecond, time.Millisecond))
+	})
+})
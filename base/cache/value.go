@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -13,68 +14,110 @@ type ValueCache[T any] struct {
 	data           T
 	expireRefresh  int64
 	expireRotten   int64
+	loadDuration   int64
+	generation     uint64 // incremented by every store, so that a load started before it is discarded
 	mutex          sync.Mutex
 	semaphore      *semaphore.Weighted
 	timeoutRefresh int64
 	timeoutRotten  int64
+	options        *options
 	onRefresh      func() // for test
 }
 
-func NewValueCache[T any](clock clock.Clock, timeoutRefresh time.Duration, timeoutRotten time.Duration) *ValueCache[T] {
+func NewValueCache[T any](clock clock.Clock, timeoutRefresh time.Duration, timeoutRotten time.Duration, opts ...Option) *ValueCache[T] {
 	return &ValueCache[T]{
 		clock:          clock,
 		expireRefresh:  0,
 		expireRotten:   0,
 		mutex:          sync.Mutex{},
 		semaphore:      semaphore.NewWeighted(1),
-		timeoutRefresh: int64(timeoutRefresh.Seconds()),
-		timeoutRotten:  int64(timeoutRotten.Seconds()),
+		timeoutRefresh: int64(timeoutRefresh),
+		timeoutRotten:  int64(timeoutRotten),
+		options:        newOptions(opts),
 	}
 }
 
 func (c *ValueCache[T]) Get(getter func() (T, error)) (T, error) {
-	now := c.clock.Now().Unix()
+	return c.GetContext(context.Background(), func(context.Context) (T, error) {
+		return getter()
+	})
+}
+
+func (c *ValueCache[T]) GetContext(ctx context.Context, getter func(ctx context.Context) (T, error)) (T, error) {
+	now := c.clock.Now().UnixNano()
 
-	if now < c.expireRefresh {
+	c.mutex.Lock()
+
+	if now < c.expireRefresh && !c.options.shouldRefreshEarly(now, c.expireRefresh, c.loadDuration) {
+		defer c.mutex.Unlock()
 		return c.data, nil
 	}
 
 	if now < c.expireRotten {
+		defer c.mutex.Unlock()
+
 		isAcquired := c.semaphore.TryAcquire(1)
 		if !isAcquired {
 			return c.data, nil
 		}
-		data := c.data
-		go func() {
-			defer c.semaphore.Release(1)
-			data, err := getter()
-			if err != nil {
-				return
+		generation := c.generation
+		c.options.executor.Go(func() {
+			data, loadDuration, err := c.load(context.Background(), now, getter)
+
+			c.mutex.Lock()
+			stored := err == nil && c.generation == generation
+			if stored {
+				c.store(now, data, loadDuration)
 			}
-			c.data = data
-			c.expireRefresh = now + c.timeoutRefresh
-			c.expireRotten = now + c.timeoutRotten
-			if c.onRefresh != nil {
+			c.mutex.Unlock()
+			c.semaphore.Release(1)
+
+			if stored && c.onRefresh != nil {
 				c.onRefresh()
 			}
-		}()
-		return data, nil
+			c.options.notifyLoad(LoadEvent{Refresh: true, Stored: stored, Err: err})
+		})
+		return c.data, nil
 	}
 
+	// The load may retry with backoff, so it runs without the lock. Concurrent misses each load,
+	// the first to finish is stored, and the others return what it stored.
+	generation := c.generation
+	c.mutex.Unlock()
+
+	data, loadDuration, err := c.load(ctx, now, getter)
+
 	c.mutex.Lock()
-	defer c.mutex.Unlock()
+	stored := err == nil && c.generation == generation
+	if stored {
+		c.store(now, data, loadDuration)
+	} else if err == nil {
+		data = c.data
+	}
+	c.mutex.Unlock()
 
-	data, err := getter()
-	if err != nil {
-		return *new(T), err
+	if stored && c.onRefresh != nil {
+		c.onRefresh()
 	}
+	c.options.notifyLoad(LoadEvent{Refresh: false, Stored: stored, Err: err})
+	return data, err
+}
 
+func (c *ValueCache[T]) store(now int64, data T, loadDuration int64) {
+	c.generation++
 	c.data = data
-	c.expireRefresh = now + c.timeoutRefresh
+	c.expireRefresh = now + c.options.refreshTimeout(c.timeoutRefresh, c.timeoutRotten)
 	c.expireRotten = now + c.timeoutRotten
-	if c.onRefresh != nil {
-		c.onRefresh()
-	}
+	c.loadDuration = loadDuration
+}
 
-	return data, nil
+func (c *ValueCache[T]) load(ctx context.Context, start int64, getter func(ctx context.Context) (T, error)) (T, int64, error) {
+	data, err := retryLoad(ctx, c.clock, c.options, getter)
+	if err != nil {
+		return *new(T), 0, err
+	}
+	if !c.options.measureLoad() {
+		return data, 0, nil
+	}
+	return data, c.clock.Now().UnixNano() - start, nil
 }
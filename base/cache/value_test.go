@@ -3,7 +3,6 @@ package cache
 import (
 	"errors"
 	"sync"
-	"testing"
 	"time"
 
 	"github.com/omnius-labs/core-go/base/clock"
@@ -11,21 +10,8 @@ import (
 	. "github.com/onsi/gomega"
 )
 
-func TestValueCache(t *testing.T) {
-	RegisterFailHandler(Fail)
-	RunSpecs(t, "ValueCache Spec")
-}
-
 var _ = Describe("Success Test", func() {
-	c := clock.NewMock(
-		[]time.Time{
-			time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 10, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 10, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 60, 0, time.UTC),
-		},
-	)
+	c := clock.NewFake(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
 	fr := 0
 	f := func() (int, error) {
 		fr++
@@ -52,6 +38,7 @@ var _ = Describe("Success Test", func() {
 	})
 
 	It("use cache, second fetch", func() {
+		c.Advance(10 * time.Second)
 		wg.Add(1)
 		ret, err := vc.Get(f)
 		Expect(ret).To(Equal(1))
@@ -65,6 +52,7 @@ var _ = Describe("Success Test", func() {
 	})
 
 	It("third fetch", func() {
+		c.Advance(50 * time.Second)
 		wg.Add(1)
 		ret, err := vc.Get(f)
 		Expect(ret).To(Equal(3))
@@ -75,13 +63,7 @@ var _ = Describe("Success Test", func() {
 })
 
 var _ = Describe("Error Test", func() {
-	c := clock.NewMock(
-		[]time.Time{
-			time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC),
-			time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC),
-		},
-	)
+	c := clock.NewFake(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
 	fr := 0
 	f := func() (int, error) {
 		fr++
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+type WarmResult[K comparable] struct {
+	Loaded  int
+	Skipped int
+	Failed  map[K]error
+}
+
+// Warm loads the keys that are not cached yet through the loader, running at most concurrency loads at once.
+// Failed keys are reported in the result; the returned error is only set when ctx is done before all keys were tried.
+func (c *LoadingCache[K, V]) Warm(ctx context.Context, keys []K, concurrency int) (WarmResult[K], error) {
+	return c.warm(ctx, keys, concurrency, c.loadKey)
+}
+
+// Warm loads the keys that are not cached yet through getter, like LoadingCache.Warm.
+func (c *KeyValueCache[T]) Warm(ctx context.Context, keys []string, concurrency int, getter func(ctx context.Context, key string) (T, error)) (WarmResult[string], error) {
+	return c.warm(ctx, keys, concurrency, func(ctx context.Context, key string, _ T, _ bool) (T, error) {
+		return getter(ctx, key)
+	})
+}
+
+func (c *keyValueStore[K, V]) warm(ctx context.Context, keys []K, concurrency int, loader func(ctx context.Context, key K, old V, refresh bool) (V, error)) (WarmResult[K], error) {
+	result := WarmResult[K]{Failed: make(map[K]error)}
+	mutex := sync.Mutex{}
+	done := 0
+
+	finish := func(key K, loaded bool, err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		switch {
+		case err != nil:
+			result.Failed[key] = err
+		case loaded:
+			result.Loaded++
+		default:
+			result.Skipped++
+		}
+		done++
+		if c.options.warmProgress != nil {
+			c.options.warmProgress(done, len(keys))
+		}
+	}
+
+	g := errgroup.Group{}
+	g.SetLimit(max(concurrency, 1))
+
+	for _, key := range keys {
+		if ctx.Err() != nil {
+			break
+		}
+		g.Go(func() error {
+			if _, _, ok := c.lookup(key); ok {
+				finish(key, false, nil)
+				return nil
+			}
+			err := c.preload(ctx, key, func(ctx context.Context, old V, refresh bool) (V, error) {
+				return loader(ctx, key, old, refresh)
+			})
+			finish(key, err == nil, err)
+			return nil
+		})
+	}
+	g.Wait()
+
+	return result, ctx.Err()
+}
@@ -0,0 +1,140 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/cache"
+	"github.com/omnius-labs/core-go/base/cache/cachetest"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Warm Test", func() {
+	errNotFound := errors.New("not found")
+	newLoader := func(stub *cachetest.Loader[int]) cache.Loader[string, int] {
+		return cache.LoaderFunc[string, int](func(ctx context.Context, key string) (int, error) {
+			if key == "missing" {
+				return 0, errNotFound
+			}
+			return stub.GetContext(ctx)
+		})
+	}
+
+	It("loads keys with bounded concurrency", func() {
+		c := cachetest.NewClock(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+		stub := cachetest.NewCountingLoader()
+		mutex := sync.Mutex{}
+		var progress []int
+		lc := cache.NewLoadingCache[string, int](c, 10, 5*time.Second, 30*time.Second, newLoader(stub), cache.WithWarmProgress(func(done int, total int) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			progress = append(progress, done)
+			Expect(total).To(Equal(5))
+		}))
+
+		_, err := lc.Get(context.Background(), "a")
+		Expect(err).NotTo(HaveOccurred())
+
+		stub.Block()
+		type warmed struct {
+			result cache.WarmResult[string]
+			err    error
+		}
+		ch := make(chan warmed)
+		go func() {
+			result, err := lc.Warm(context.Background(), []string{"a", "b", "c", "d", "missing"}, 2)
+			ch <- warmed{result, err}
+		}()
+
+		Expect(stub.AwaitBlocked(2)).To(Succeed())
+		Expect(stub.Calls()).To(Equal(3))
+		stub.Unblock()
+
+		w := <-ch
+		Expect(w.err).NotTo(HaveOccurred())
+		Expect(w.result.Loaded).To(Equal(3))
+		Expect(w.result.Skipped).To(Equal(1))
+		Expect(w.result.Failed).To(Equal(map[string]error{"missing": errNotFound}))
+		Expect(progress).To(Equal([]int{1, 2, 3, 4, 5}))
+		Expect(lc.Len()).To(Equal(4))
+	})
+
+	It("stops when context is canceled", func() {
+		c := cachetest.NewClock(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+		stub := cachetest.NewCountingLoader()
+		lc := cache.NewLoadingCache[string, int](c, 10, 5*time.Second, 30*time.Second, newLoader(stub))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		result, err := lc.Warm(ctx, []string{"a", "b"}, 1)
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(result.Loaded).To(Equal(0))
+		Expect(stub.Calls()).To(Equal(0))
+	})
+
+	It("exports hot keys for the next instance", func() {
+		c := cachetest.NewClock(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+		stub := cachetest.NewCountingLoader()
+		lc := cache.NewLoadingCache[string, int](c, 10, 5*time.Second, 30*time.Second, newLoader(stub), cache.WithHotKeyTracking(2))
+		ctx := context.Background()
+
+		for _, key := range []string{"a", "b", "b", "c", "c", "c", "a", "a", "c"} {
+			_, err := lc.Get(ctx, key)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(lc.HotKeys()).To(Equal([]string{"c", "a"}))
+
+		next := cache.NewLoadingCache[string, int](c, 10, 5*time.Second, 30*time.Second, newLoader(stub))
+		result, err := next.Warm(ctx, lc.HotKeys(), 4)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Loaded).To(Equal(2))
+		Expect(next.Keys()).To(ConsistOf("c", "a"))
+	})
+
+	It("warms a key value cache through a getter", func() {
+		c := cachetest.NewClock(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+		vc := cache.NewKeyValueCache[string](c, 10, 5*time.Second, 30*time.Second)
+
+		result, err := vc.Warm(context.Background(), []string{"a", "b"}, 2, func(ctx context.Context, key string) (string, error) {
+			return key + "!", nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Loaded).To(Equal(2))
+
+		ret, _, ok := vc.Lookup("b")
+		Expect(ret).To(Equal("b!"))
+		Expect(ok).To(BeTrue())
+	})
+
+	It("does not overwrite a value stored by Get during a slow load", func() {
+		c := cachetest.NewClock(time.Date(2000, time.January, 1, 1, 0, 0, 0, time.UTC))
+		stub := cachetest.NewLoader(func(call int) (int, error) {
+			return -call, nil
+		})
+		vc := cache.NewKeyValueCache[int](c, 10, 5*time.Second, 30*time.Second)
+
+		stub.Block()
+		ch := make(chan cache.WarmResult[string])
+		go func() {
+			result, _ := vc.Warm(context.Background(), []string{"a"}, 1, func(ctx context.Context, key string) (int, error) {
+				return stub.GetContext(ctx)
+			})
+			ch <- result
+		}()
+		Expect(stub.AwaitBlocked(1)).To(Succeed())
+
+		ret, err := vc.Get("a", func() (int, error) { return 10, nil })
+		Expect(ret).To(Equal(10))
+		Expect(err).NotTo(HaveOccurred())
+
+		stub.Unblock()
+		<-ch
+
+		ret, _, ok := vc.Lookup("a")
+		Expect(ret).To(Equal(10))
+		Expect(ok).To(BeTrue())
+	})
+})
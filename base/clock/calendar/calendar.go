@@ -0,0 +1,181 @@
+package calendar
+
+import (
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	"github.com/pkg/errors"
+)
+
+type date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+func dateOf(t time.Time) date {
+	y, m, d := t.Date()
+	return date{y, m, d}
+}
+
+// BusinessHours is the opening time of a business day, as offsets from midnight.
+type BusinessHours struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// Calendar knows the weekends, holidays and business hours of a place.
+// Dates are evaluated in its location, whatever the location of the times passed in.
+type Calendar struct {
+	name     string
+	location *time.Location
+	weekend  map[time.Weekday]bool
+	holidays map[date]string
+	hours    BusinessHours
+}
+
+// New returns a calendar without holidays. The weekend must leave at least one business day in the week,
+// and the business hours must start before they end. A nil location means UTC.
+func New(name string, location *time.Location, weekend []time.Weekday, hours BusinessHours) (*Calendar, error) {
+	if hours.Start < 0 || hours.Start >= hours.End {
+		return nil, errors.Errorf("calendar %q: business hours %s to %s are empty", name, hours.Start, hours.End)
+	}
+	if location == nil {
+		location = time.UTC
+	}
+
+	c := &Calendar{
+		name:     name,
+		location: location,
+		weekend:  make(map[time.Weekday]bool),
+		holidays: make(map[date]string),
+		hours:    hours,
+	}
+	for _, w := range weekend {
+		c.weekend[w] = true
+	}
+	if len(c.weekend) >= 7 {
+		return nil, errors.Errorf("calendar %q: every day is a weekend day", name)
+	}
+	return c, nil
+}
+
+func (c *Calendar) Name() string {
+	return c.name
+}
+
+func (c *Calendar) Location() *time.Location {
+	return c.location
+}
+
+func (c *Calendar) AddHoliday(year int, month time.Month, day int, name string) {
+	c.holidays[date{year, month, day}] = name
+}
+
+// Holiday returns the name of the holiday on the day of t.
+func (c *Calendar) Holiday(t time.Time) (string, bool) {
+	name, ok := c.holidays[dateOf(t.In(c.location))]
+	return name, ok
+}
+
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	t = t.In(c.location)
+	if c.weekend[t.Weekday()] {
+		return false
+	}
+	_, holiday := c.holidays[dateOf(t)]
+	return !holiday
+}
+
+// AddBusinessDays moves t by n business days, keeping its wall clock time. With n == 0, t is returned as is,
+// even if it is not a business day. It returns the zero time if holidays leave no business day to move to.
+func (c *Calendar) AddBusinessDays(t time.Time, n int) time.Time {
+	t = t.In(c.location)
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	gap := 0
+	for n > 0 {
+		t = AddDays(t, step)
+		if !c.IsBusinessDay(t) {
+			if gap++; gap > c.maxGap() {
+				return time.Time{}
+			}
+			continue
+		}
+		gap = 0
+		n--
+	}
+	return t
+}
+
+// maxGap bounds the run of days without a business day, since every week has one that is not a holiday.
+func (c *Calendar) maxGap() int {
+	return 7 * (len(c.holidays) + 1)
+}
+
+// NextBusinessDay returns the start of the first business day after the day of t, or the zero time if there is none.
+func (c *Calendar) NextBusinessDay(t time.Time) time.Time {
+	return c.AddBusinessDays(StartOf(t, Day, c.location), 1)
+}
+
+// PreviousBusinessDay returns the start of the last business day before the day of t, or the zero time if there is none.
+func (c *Calendar) PreviousBusinessDay(t time.Time) time.Time {
+	return c.AddBusinessDays(StartOf(t, Day, c.location), -1)
+}
+
+// BusinessDaysBetween counts the business days in [from, to), by day. It is negative if to is before from.
+func (c *Calendar) BusinessDaysBetween(from time.Time, to time.Time) int {
+	start := StartOf(from, Day, c.location)
+	end := StartOf(to, Day, c.location)
+	sign := 1
+	if end.Before(start) {
+		start, end, sign = end, start, -1
+	}
+
+	n := 0
+	for d := start; d.Before(end); d = AddDays(d, 1) {
+		if c.IsBusinessDay(d) {
+			n++
+		}
+	}
+	return sign * n
+}
+
+// LastBusinessDayOf returns the start of the last business day in the period containing t,
+// e.g. a billing cutoff at the end of the month. It returns the zero time if the period has none.
+func (c *Calendar) LastBusinessDayOf(t time.Time, p Period) time.Time {
+	start := StartOf(t, p, c.location)
+	for d := AddDays(EndOf(t, p, c.location), -1); !d.Before(start); d = AddDays(d, -1) {
+		if c.IsBusinessDay(d) {
+			return d
+		}
+	}
+	return time.Time{}
+}
+
+// IsBusinessHours reports whether t falls within the business hours of a business day.
+func (c *Calendar) IsBusinessHours(t time.Time) bool {
+	t = t.In(c.location)
+	if !c.IsBusinessDay(t) {
+		return false
+	}
+	// The wall clock time, not the time elapsed since midnight, which differs on DST days.
+	hour, min, sec := t.Clock()
+	offset := time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute + time.Duration(sec)*time.Second
+	return offset >= c.hours.Start && offset < c.hours.End
+}
+
+// Today returns the start of the current day in the calendar's location.
+func (c *Calendar) Today(clk clock.Clock) time.Time {
+	return StartOf(clk.Now(), Day, c.location)
+}
+
+func (c *Calendar) IsBusinessDayNow(clk clock.Clock) bool {
+	return c.IsBusinessDay(clk.Now())
+}
+
+func (c *Calendar) IsBusinessHoursNow(clk clock.Clock) bool {
+	return c.IsBusinessHours(clk.Now())
+}
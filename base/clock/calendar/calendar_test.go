@@ -0,0 +1,141 @@
+package calendar
+
+import (
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Calendar Test", func() {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	jst := func(month time.Month, day int, hour int) time.Time {
+		return time.Date(2024, month, day, hour, 0, 0, 0, tokyo)
+	}
+
+	var c *Calendar
+	BeforeEach(func() {
+		var err error
+		c, err = LoadFile("testdata/jp.yaml")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("loads holidays", func() {
+		Expect(c.Name()).To(Equal("jp"))
+		Expect(c.Location()).To(Equal(tokyo))
+
+		name, ok := c.Holiday(jst(time.January, 8, 10))
+		Expect(ok).To(BeTrue())
+		Expect(name).To(Equal("Coming of Age Day"))
+
+		// 2024-01-07 16:00 UTC is already 2024-01-08 in Tokyo.
+		_, ok = c.Holiday(time.Date(2024, time.January, 7, 16, 0, 0, 0, time.UTC))
+		Expect(ok).To(BeTrue())
+	})
+
+	It("knows business days", func() {
+		Expect(c.IsBusinessDay(jst(time.January, 5, 10))).To(BeTrue())
+		Expect(c.IsBusinessDay(jst(time.January, 6, 10))).To(BeFalse())
+		Expect(c.IsBusinessDay(jst(time.January, 8, 10))).To(BeFalse())
+	})
+
+	It("adds business days", func() {
+		// Friday 2024-01-05 + 1 skips the weekend and the Monday holiday.
+		Expect(c.AddBusinessDays(jst(time.January, 5, 15), 1)).To(Equal(jst(time.January, 9, 15)))
+		Expect(c.AddBusinessDays(jst(time.January, 9, 15), -1)).To(Equal(jst(time.January, 5, 15)))
+		Expect(c.AddBusinessDays(jst(time.January, 9, 15), 5)).To(Equal(jst(time.January, 16, 15)))
+		Expect(c.AddBusinessDays(jst(time.January, 6, 15), 0)).To(Equal(jst(time.January, 6, 15)))
+
+		Expect(c.NextBusinessDay(jst(time.January, 5, 15))).To(Equal(jst(time.January, 9, 0)))
+		Expect(c.PreviousBusinessDay(jst(time.January, 2, 15))).To(Equal(time.Date(2023, time.December, 29, 0, 0, 0, 0, tokyo)))
+	})
+
+	It("counts business days", func() {
+		Expect(c.BusinessDaysBetween(jst(time.January, 1, 0), jst(time.February, 1, 0))).To(Equal(21))
+		Expect(c.BusinessDaysBetween(jst(time.February, 1, 0), jst(time.January, 1, 0))).To(Equal(-21))
+		Expect(c.BusinessDaysBetween(jst(time.January, 5, 0), jst(time.January, 5, 23))).To(Equal(0))
+	})
+
+	It("finds the last business day of a period", func() {
+		// 2024-03-31 is a Sunday.
+		Expect(c.LastBusinessDayOf(jst(time.March, 10, 0), Month)).To(Equal(jst(time.March, 29, 0)))
+	})
+
+	It("knows business hours", func() {
+		Expect(c.IsBusinessHours(jst(time.January, 5, 9))).To(BeTrue())
+		Expect(c.IsBusinessHours(jst(time.January, 5, 17))).To(BeTrue())
+		Expect(c.IsBusinessHours(jst(time.January, 5, 18))).To(BeFalse())
+		Expect(c.IsBusinessHours(jst(time.January, 5, 8))).To(BeFalse())
+		Expect(c.IsBusinessHours(jst(time.January, 6, 12))).To(BeFalse())
+	})
+
+	It("uses the clock for the current time", func() {
+		fc := clock.NewFake(time.Date(2024, time.January, 5, 1, 0, 0, 0, time.UTC))
+		Expect(c.Today(fc)).To(Equal(jst(time.January, 5, 0)))
+		Expect(c.IsBusinessDayNow(fc)).To(BeTrue())
+		Expect(c.IsBusinessHoursNow(fc)).To(BeTrue())
+
+		fc.Advance(12 * time.Hour)
+		Expect(c.IsBusinessHoursNow(fc)).To(BeFalse())
+	})
+
+	It("parses json with defaults", func() {
+		c, err := ParseJSON([]byte(`{"name": "us", "holidays": [{"date": "2024-07-04", "name": "Independence Day"}]}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Location()).To(Equal(time.UTC))
+		Expect(c.IsBusinessDay(time.Date(2024, time.July, 4, 12, 0, 0, 0, time.UTC))).To(BeFalse())
+		Expect(c.IsBusinessDay(time.Date(2024, time.July, 6, 12, 0, 0, 0, time.UTC))).To(BeFalse())
+		Expect(c.IsBusinessHours(time.Date(2024, time.July, 5, 16, 59, 0, 0, time.UTC))).To(BeTrue())
+	})
+
+	It("supports other weekends", func() {
+		c, err := ParseYAML([]byte("name: ae\nweekend: [sat, sun]\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.IsBusinessDay(time.Date(2024, time.July, 5, 12, 0, 0, 0, time.UTC))).To(BeTrue())
+
+		c, err = ParseYAML([]byte("name: il\nweekend: [friday, saturday]\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.IsBusinessDay(time.Date(2024, time.July, 5, 12, 0, 0, 0, time.UTC))).To(BeFalse())
+		Expect(c.IsBusinessDay(time.Date(2024, time.July, 7, 12, 0, 0, 0, time.UTC))).To(BeTrue())
+	})
+
+	DescribeTable("rejects invalid files",
+		func(data string) {
+			_, err := ParseYAML([]byte(data))
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("location", "location: Nowhere/Land"),
+		Entry("weekday", "weekend: [someday]"),
+		Entry("hours", "business_hours: {start: '9am', end: '17:00'}"),
+		Entry("date", "holidays: [{date: '2024/01/01', name: x}]"),
+		Entry("syntax", "holidays: ["),
+		Entry("all weekend", "weekend: [sun, mon, tue, wed, thu, fri, sat]"),
+		Entry("empty hours", "business_hours: {start: '17:00', end: '09:00'}"),
+	)
+
+	It("defaults to UTC", func() {
+		c, err := New("utc", nil, []time.Weekday{time.Saturday, time.Sunday}, BusinessHours{Start: 9 * time.Hour, End: 17 * time.Hour})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(c.Location()).To(Equal(time.UTC))
+		Expect(c.IsBusinessHours(time.Date(2024, time.July, 5, 9, 0, 0, 0, tokyo))).To(BeFalse())
+		Expect(c.IsBusinessHours(time.Date(2024, time.July, 5, 9, 0, 0, 0, time.UTC))).To(BeTrue())
+	})
+
+	It("skips long runs of holidays", func() {
+		c, err := New("closed", time.UTC, []time.Weekday{time.Saturday, time.Sunday}, BusinessHours{Start: 9 * time.Hour, End: 17 * time.Hour})
+		Expect(err).NotTo(HaveOccurred())
+		for d := time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC); d.Month() == time.July; d = AddDays(d, 1) {
+			c.AddHoliday(d.Year(), d.Month(), d.Day(), "closed")
+		}
+		Expect(c.NextBusinessDay(time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC))).To(Equal(time.Date(2024, time.August, 1, 0, 0, 0, 0, time.UTC)))
+
+		for y := 2024; y < 2026; y++ {
+			for d := time.Date(y, time.January, 1, 0, 0, 0, 0, time.UTC); d.Year() == y; d = AddDays(d, 1) {
+				c.AddHoliday(d.Year(), d.Month(), d.Day(), "closed")
+			}
+		}
+		Expect(c.NextBusinessDay(time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC))).To(Equal(time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)))
+		Expect(c.PreviousBusinessDay(time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC))).To(Equal(time.Date(2023, time.December, 29, 0, 0, 0, 0, time.UTC)))
+	})
+})
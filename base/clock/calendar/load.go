@@ -0,0 +1,138 @@
+package calendar
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// config is the file format of a calendar:
+//
+//	name: jp
+//	location: Asia/Tokyo
+//	weekend: [saturday, sunday]
+//	business_hours: {start: "09:00", end: "18:00"}
+//	holidays:
+//	  - {date: 2024-01-01, name: New Year's Day}
+type config struct {
+	Name          string          `yaml:"name" json:"name"`
+	Location      string          `yaml:"location" json:"location"`
+	Weekend       []string        `yaml:"weekend" json:"weekend"`
+	BusinessHours *hoursConfig    `yaml:"business_hours" json:"business_hours"`
+	Holidays      []holidayConfig `yaml:"holidays" json:"holidays"`
+}
+
+type hoursConfig struct {
+	Start string `yaml:"start" json:"start"`
+	End   string `yaml:"end" json:"end"`
+}
+
+type holidayConfig struct {
+	Date string `yaml:"date" json:"date"`
+	Name string `yaml:"name" json:"name"`
+}
+
+// LoadFile reads a calendar from a .yaml, .yml or .json file.
+func LoadFile(path string) (*Calendar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return ParseYAML(data)
+	case ".json":
+		return ParseJSON(data)
+	default:
+		return nil, errors.Errorf("unknown calendar file type %q", path)
+	}
+}
+
+func ParseYAML(data []byte) (*Calendar, error) {
+	var cfg config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, errors.Wrap(err, "parse calendar yaml")
+	}
+	return cfg.build()
+}
+
+func ParseJSON(data []byte) (*Calendar, error) {
+	var cfg config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, errors.Wrap(err, "parse calendar json")
+	}
+	return cfg.build()
+}
+
+func (cfg *config) build() (*Calendar, error) {
+	location := time.UTC
+	if cfg.Location != "" {
+		l, err := time.LoadLocation(cfg.Location)
+		if err != nil {
+			return nil, errors.Wrapf(err, "calendar %q", cfg.Name)
+		}
+		location = l
+	}
+
+	weekend := []time.Weekday{time.Saturday, time.Sunday}
+	if cfg.Weekend != nil {
+		weekend = weekend[:0]
+		for _, name := range cfg.Weekend {
+			w, err := parseWeekday(name)
+			if err != nil {
+				return nil, errors.Wrapf(err, "calendar %q", cfg.Name)
+			}
+			weekend = append(weekend, w)
+		}
+	}
+
+	hours := BusinessHours{Start: 9 * time.Hour, End: 17 * time.Hour}
+	if cfg.BusinessHours != nil {
+		var err error
+		if hours.Start, err = parseTimeOfDay(cfg.BusinessHours.Start); err != nil {
+			return nil, errors.Wrapf(err, "calendar %q", cfg.Name)
+		}
+		if hours.End, err = parseTimeOfDay(cfg.BusinessHours.End); err != nil {
+			return nil, errors.Wrapf(err, "calendar %q", cfg.Name)
+		}
+	}
+
+	c, err := New(cfg.Name, location, weekend, hours)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range cfg.Holidays {
+		d, err := time.Parse(time.DateOnly, h.Date)
+		if err != nil {
+			return nil, errors.Wrapf(err, "calendar %q: holiday %q", cfg.Name, h.Name)
+		}
+		c.AddHoliday(d.Year(), d.Month(), d.Day(), h.Name)
+	}
+	return c, nil
+}
+
+func parseWeekday(name string) (time.Weekday, error) {
+	for w := time.Sunday; w <= time.Saturday; w++ {
+		if strings.EqualFold(name, w.String()) || strings.EqualFold(name, w.String()[:3]) {
+			return w, nil
+		}
+	}
+	return 0, errors.Errorf("invalid weekday %q", name)
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	if s == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, errors.Errorf("invalid time of day %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
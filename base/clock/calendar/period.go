@@ -0,0 +1,63 @@
+package calendar
+
+import (
+	"time"
+)
+
+type Period int
+
+const (
+	Day Period = iota
+	// Week starts on Monday.
+	Week
+	Month
+	Quarter
+	Year
+)
+
+// StartOf returns the first instant of the period containing t, in loc.
+func StartOf(t time.Time, p Period, loc *time.Location) time.Time {
+	t = t.In(loc)
+	y, m, d := t.Date()
+
+	switch p {
+	case Week:
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, loc)
+	case Month:
+		return time.Date(y, m, 1, 0, 0, 0, 0, loc)
+	case Quarter:
+		return time.Date(y, m-(m-1)%3, 1, 0, 0, 0, 0, loc)
+	case Year:
+		return time.Date(y, time.January, 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, loc)
+	}
+}
+
+// EndOf returns the first instant of the next period, so that the period containing t is [StartOf, EndOf).
+func EndOf(t time.Time, p Period, loc *time.Location) time.Time {
+	start := StartOf(t, p, loc)
+	y, m, d := start.Date()
+
+	switch p {
+	case Week:
+		return time.Date(y, m, d+7, 0, 0, 0, 0, loc)
+	case Month:
+		return time.Date(y, m+1, 1, 0, 0, 0, 0, loc)
+	case Quarter:
+		return time.Date(y, m+3, 1, 0, 0, 0, 0, loc)
+	case Year:
+		return time.Date(y+1, time.January, 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+	}
+}
+
+// AddDays moves t by n calendar days keeping its wall clock time, unlike t.Add(n * 24 * time.Hour)
+// which is off by an hour across a DST change. A wall clock time skipped by DST moves forward.
+func AddDays(t time.Time, n int) time.Time {
+	y, m, d := t.Date()
+	hour, min, sec := t.Clock()
+	return time.Date(y, m, d+n, hour, min, sec, t.Nanosecond(), t.Location())
+}
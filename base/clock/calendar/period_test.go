@@ -0,0 +1,45 @@
+package calendar
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Period Test", func() {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	DescribeTable("start and end of period",
+		func(p Period, start time.Time, end time.Time) {
+			// 2024-05-15 (Wednesday) 03:00 in Tokyo is still 2024-05-14 in UTC.
+			t := time.Date(2024, time.May, 14, 18, 0, 0, 0, time.UTC)
+			Expect(StartOf(t, p, tokyo)).To(BeTemporally("==", start))
+			Expect(EndOf(t, p, tokyo)).To(BeTemporally("==", end))
+		},
+		Entry("day", Day, time.Date(2024, time.May, 15, 0, 0, 0, 0, tokyo), time.Date(2024, time.May, 16, 0, 0, 0, 0, tokyo)),
+		Entry("week", Week, time.Date(2024, time.May, 13, 0, 0, 0, 0, tokyo), time.Date(2024, time.May, 20, 0, 0, 0, 0, tokyo)),
+		Entry("month", Month, time.Date(2024, time.May, 1, 0, 0, 0, 0, tokyo), time.Date(2024, time.June, 1, 0, 0, 0, 0, tokyo)),
+		Entry("quarter", Quarter, time.Date(2024, time.April, 1, 0, 0, 0, 0, tokyo), time.Date(2024, time.July, 1, 0, 0, 0, 0, tokyo)),
+		Entry("year", Year, time.Date(2024, time.January, 1, 0, 0, 0, 0, tokyo), time.Date(2025, time.January, 1, 0, 0, 0, 0, tokyo)),
+	)
+
+	It("starts the week on Monday from a Sunday", func() {
+		sunday := time.Date(2024, time.May, 19, 12, 0, 0, 0, time.UTC)
+		Expect(StartOf(sunday, Week, time.UTC)).To(Equal(time.Date(2024, time.May, 13, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("has a 23-hour day across DST", func() {
+		t := time.Date(2024, time.March, 10, 12, 0, 0, 0, newYork)
+		Expect(EndOf(t, Day, newYork).Sub(StartOf(t, Day, newYork))).To(Equal(23 * time.Hour))
+	})
+
+	It("adds days keeping the wall clock time across DST", func() {
+		t := time.Date(2024, time.March, 9, 12, 0, 0, 0, newYork)
+		next := AddDays(t, 1)
+		Expect(next).To(Equal(time.Date(2024, time.March, 10, 12, 0, 0, 0, newYork)))
+		Expect(next.Sub(t)).To(Equal(23 * time.Hour))
+		Expect(AddDays(next, -1)).To(Equal(t))
+	})
+})
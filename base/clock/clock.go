@@ -1,9 +1,43 @@
 package clock
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type Clock interface {
 	Now() time.Time
+	Since(t time.Time) time.Duration
+	Until(t time.Time) time.Duration
+	Sleep(ctx context.Context, d time.Duration) error
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+type Timer interface {
+	// C returns the channel the time is sent on. It is nil for timers created by AfterFunc.
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+	Reset(d time.Duration)
+}
+
+// Executor runs callbacks that would otherwise each get a goroutine, such as the AfterFunc callbacks
+// of a FakeClock, cache refreshes or scheduled jobs, so that tests can step them explicitly.
+type Executor interface {
+	Go(f func())
+}
+
+// Rand returns random numbers in [0, 1) for jitter, so that tests can make it predictable.
+type Rand interface {
+	Float64() float64
 }
 
 var _ Clock = (*ClockImpl)(nil)
@@ -18,14 +52,92 @@ func (c *ClockImpl) Now() time.Time {
 	return time.Now()
 }
 
+func (c *ClockImpl) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (c *ClockImpl) Until(t time.Time) time.Duration {
+	return time.Until(t)
+}
+
+func (c *ClockImpl) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (c *ClockImpl) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (c *ClockImpl) NewTimer(d time.Duration) Timer {
+	return &realTimer{time.NewTimer(d)}
+}
+
+func (c *ClockImpl) NewTicker(d time.Duration) Ticker {
+	return &realTicker{time.NewTicker(d)}
+}
+
+func (c *ClockImpl) AfterFunc(d time.Duration, f func()) Timer {
+	return &realTimer{time.AfterFunc(d, f)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (t *realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t *realTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+func (t *realTimer) Reset(d time.Duration) bool {
+	return t.timer.Reset(d)
+}
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (t *realTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+func (t *realTicker) Stop() {
+	t.ticker.Stop()
+}
+
+func (t *realTicker) Reset(d time.Duration) {
+	t.ticker.Reset(d)
+}
+
+// ClockMock returns pre-recorded times from Now. Timers fire as the returned times pass their deadline.
+//
+// Deprecated: ClockMock panics once the times run out, so tests have to know how many times Now is called.
+// Use FakeClock instead.
 type ClockMock struct {
-	data []time.Time
+	data   []time.Time
+	slept  []time.Duration
+	timers *fakeTimers
 }
 
 var _ Clock = (*ClockMock)(nil)
 
 func NewMock(data []time.Time) *ClockMock {
-	return &ClockMock{data: data}
+	var start time.Time
+	if len(data) > 0 {
+		start = data[0]
+	}
+	return &ClockMock{data: data, timers: newFakeTimers(start)}
 }
 
 func (c *ClockMock) Now() time.Time {
@@ -35,5 +147,43 @@ func (c *ClockMock) Now() time.Time {
 
 	now := c.data[0]
 	c.data = c.data[1:]
+	c.timers.advanceTo(now)
 	return now
 }
+
+func (c *ClockMock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *ClockMock) Until(t time.Time) time.Duration {
+	return t.Sub(c.Now())
+}
+
+// Sleep returns immediately and records the requested duration.
+func (c *ClockMock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.slept = append(c.slept, d)
+	return nil
+}
+
+func (c *ClockMock) Slept() []time.Duration {
+	return c.slept
+}
+
+func (c *ClockMock) After(d time.Duration) <-chan time.Time {
+	return c.timers.newTimer(d, nil).C()
+}
+
+func (c *ClockMock) NewTimer(d time.Duration) Timer {
+	return c.timers.newTimer(d, nil)
+}
+
+func (c *ClockMock) NewTicker(d time.Duration) Ticker {
+	return c.timers.newTicker(d)
+}
+
+func (c *ClockMock) AfterFunc(d time.Duration, f func()) Timer {
+	return c.timers.newTimer(d, f)
+}
@@ -0,0 +1,82 @@
+package clock
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClockMock Timer Test", func() {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	It("fires timers as Now passes their deadline", func() {
+		c := NewMock([]time.Time{start, start.Add(500 * time.Millisecond), start.Add(2 * time.Second)})
+
+		Expect(c.Now()).To(Equal(start))
+		ch := c.After(time.Second)
+
+		c.Now()
+		Expect(ch).NotTo(Receive())
+		c.Now()
+		Expect(ch).To(Receive(Equal(start.Add(time.Second))))
+	})
+
+	It("fires timers in deadline order", func() {
+		c := NewMock([]time.Time{start, start.Add(1500 * time.Millisecond), start.Add(3500 * time.Millisecond)})
+		c.Now()
+		t2 := c.NewTimer(2 * time.Second)
+		t1 := c.NewTimer(1 * time.Second)
+		t3 := c.NewTimer(3 * time.Second)
+
+		c.Now()
+		Expect(t1.C()).To(Receive(Equal(start.Add(1 * time.Second))))
+		Expect(t2.C()).NotTo(Receive())
+
+		c.Now()
+		Expect(t2.C()).To(Receive(Equal(start.Add(2 * time.Second))))
+		Expect(t3.C()).To(Receive(Equal(start.Add(3 * time.Second))))
+	})
+
+	It("stops and resets timers", func() {
+		c := NewMock([]time.Time{start, start.Add(time.Second), start.Add(2 * time.Second)})
+		c.Now()
+		t := c.NewTimer(time.Second)
+		Expect(t.Stop()).To(BeTrue())
+		Expect(t.Stop()).To(BeFalse())
+
+		c.Now()
+		Expect(t.C()).NotTo(Receive())
+
+		Expect(t.Reset(time.Second)).To(BeFalse())
+		c.Now()
+		Expect(t.C()).To(Receive(Equal(start.Add(2 * time.Second))))
+	})
+
+	It("ticks and drops unreceived ticks", func() {
+		c := NewMock([]time.Time{start, start.Add(time.Second), start.Add(4 * time.Second), start.Add(5 * time.Second)})
+		c.Now()
+		ticker := c.NewTicker(time.Second)
+
+		c.Now()
+		Expect(ticker.C()).To(Receive(Equal(start.Add(1 * time.Second))))
+
+		c.Now()
+		Expect(ticker.C()).To(Receive(Equal(start.Add(2 * time.Second))))
+		Expect(ticker.C()).NotTo(Receive())
+
+		ticker.Stop()
+		c.Now()
+		Expect(ticker.C()).NotTo(Receive())
+	})
+
+	It("runs AfterFunc callbacks", func() {
+		c := NewMock([]time.Time{start, start.Add(time.Second)})
+		c.Now()
+		fired := make(chan struct{})
+		c.AfterFunc(time.Second, func() { close(fired) })
+
+		c.Now()
+		Eventually(fired).Should(BeClosed())
+	})
+})
@@ -0,0 +1,92 @@
+package clock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type contextKey struct{}
+
+func WithClock(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the clock set by WithClock, or the real clock if there is none.
+func FromContext(ctx context.Context) Clock {
+	if c, ok := ctx.Value(contextKey{}).(Clock); ok {
+		return c
+	}
+	return New()
+}
+
+func WithTimeout(ctx context.Context, c Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	return WithDeadline(ctx, c, c.Now().Add(d))
+}
+
+// WithDeadline is like context.WithDeadline, but the deadline is measured by c,
+// so with a FakeClock it expires when the clock is advanced past it.
+func WithDeadline(ctx context.Context, c Clock, deadline time.Time) (context.Context, context.CancelFunc) {
+	if _, ok := c.(*ClockImpl); ok {
+		return context.WithDeadline(ctx, deadline)
+	}
+	if cur, ok := ctx.Deadline(); ok && cur.Before(deadline) {
+		return context.WithCancel(ctx)
+	}
+
+	dc := &deadlineContext{Context: ctx, deadline: deadline, done: make(chan struct{})}
+
+	d := deadline.Sub(c.Now())
+	if d <= 0 {
+		dc.cancel(context.DeadlineExceeded)
+		return dc, func() {}
+	}
+
+	timer := c.AfterFunc(d, func() {
+		dc.cancel(context.DeadlineExceeded)
+	})
+	stop := context.AfterFunc(ctx, func() {
+		timer.Stop()
+		dc.cancel(ctx.Err())
+	})
+	return dc, func() {
+		stop()
+		timer.Stop()
+		dc.cancel(context.Canceled)
+	}
+}
+
+// deadlineContext has its own done channel rather than wrapping a context.WithCancel,
+// otherwise contexts derived from it would report Canceled instead of DeadlineExceeded.
+type deadlineContext struct {
+	context.Context
+	deadline time.Time
+	done     chan struct{}
+	mutex    sync.Mutex
+	err      error
+}
+
+func (c *deadlineContext) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *deadlineContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *deadlineContext) Err() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.err
+}
+
+func (c *deadlineContext) cancel(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	close(c.done)
+}
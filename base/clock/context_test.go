@@ -0,0 +1,71 @@
+package clock
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Context Test", func() {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	It("carries the clock", func() {
+		c := NewFake(start)
+		ctx := WithClock(context.Background(), c)
+		Expect(FromContext(ctx)).To(BeIdenticalTo(c))
+		Expect(FromContext(context.Background())).To(BeAssignableToTypeOf(&ClockImpl{}))
+	})
+
+	It("expires when the fake clock is advanced", func() {
+		c := NewFake(start)
+		ctx, cancel := WithTimeout(context.Background(), c, time.Second)
+		defer cancel()
+		child, cancelChild := context.WithCancel(ctx)
+		defer cancelChild()
+
+		deadline, ok := ctx.Deadline()
+		Expect(ok).To(BeTrue())
+		Expect(deadline).To(Equal(start.Add(time.Second)))
+
+		c.Advance(999 * time.Millisecond)
+		Consistently(ctx.Done(), 50*time.Millisecond).ShouldNot(BeClosed())
+
+		c.Advance(time.Millisecond)
+		Eventually(ctx.Done()).Should(BeClosed())
+		Expect(ctx.Err()).To(MatchError(context.DeadlineExceeded))
+		Eventually(child.Done()).Should(BeClosed())
+		Expect(child.Err()).To(MatchError(context.DeadlineExceeded))
+	})
+
+	It("expires immediately when the deadline has passed", func() {
+		c := NewFake(start)
+		ctx, cancel := WithDeadline(context.Background(), c, start.Add(-time.Second))
+		defer cancel()
+		Expect(ctx.Done()).To(BeClosed())
+		Expect(ctx.Err()).To(MatchError(context.DeadlineExceeded))
+	})
+
+	It("is canceled before the deadline", func() {
+		c := NewFake(start)
+		ctx, cancel := WithTimeout(context.Background(), c, time.Second)
+		cancel()
+		Expect(ctx.Err()).To(MatchError(context.Canceled))
+
+		c.Advance(time.Second)
+		Expect(ctx.Err()).To(MatchError(context.Canceled))
+	})
+
+	It("keeps an earlier parent deadline", func() {
+		c := NewFake(start)
+		parent, cancelParent := WithTimeout(context.Background(), c, time.Second)
+		defer cancelParent()
+		ctx, cancel := WithTimeout(parent, c, time.Minute)
+		defer cancel()
+
+		c.Advance(time.Second)
+		Eventually(ctx.Done()).Should(BeClosed())
+		Expect(ctx.Err()).To(MatchError(context.DeadlineExceeded))
+	})
+})
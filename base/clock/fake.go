@@ -0,0 +1,406 @@
+package clock
+
+import (
+	"container/heap"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// FakeClock is a clock that only moves when told to. Timers, tickers and sleeps fire as it is advanced.
+// It is safe for concurrent use.
+type FakeClock struct {
+	timers *fakeTimers
+}
+
+var _ Clock = (*FakeClock)(nil)
+
+type FakeOption func(*fakeTimers)
+
+// WithExecutor hands the AfterFunc callbacks to e as they fire, in deadline order,
+// instead of running each in its own goroutine. e.Go must not call back into the clock.
+func WithExecutor(e Executor) FakeOption {
+	return func(q *fakeTimers) {
+		q.executor = e
+	}
+}
+
+// WithAutoAdvance makes Sleep advance the clock by d instead of waiting for it,
+// for code under test that only sleeps between attempts.
+func WithAutoAdvance() FakeOption {
+	return func(q *fakeTimers) {
+		q.autoAdvance = true
+	}
+}
+
+func NewFake(now time.Time, opts ...FakeOption) *FakeClock {
+	timers := newFakeTimers(now)
+	for _, opt := range opts {
+		opt(timers)
+	}
+	return &FakeClock{timers: timers}
+}
+
+func (c *FakeClock) Now() time.Time {
+	return c.timers.current()
+}
+
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *FakeClock) Until(t time.Time) time.Duration {
+	return t.Sub(c.Now())
+}
+
+// Sleep blocks until the clock has been advanced by d or ctx is done.
+func (c *FakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	if c.timers.autoAdvance {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		c.timers.sleep(d)
+		return nil
+	}
+
+	timer := c.timers.newTimer(d, nil)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.timers.newTimer(d, nil).C()
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	return c.timers.newTimer(d, nil)
+}
+
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	return c.timers.newTicker(d)
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	return c.timers.newTimer(d, f)
+}
+
+// Set moves the clock to now. Moving it backwards does not fire anything.
+func (c *FakeClock) Set(now time.Time) {
+	c.timers.set(now)
+}
+
+func (c *FakeClock) Advance(d time.Duration) {
+	c.timers.advance(d)
+}
+
+// BlockUntil blocks until at least n timers, tickers or sleepers are waiting on the clock,
+// so that a test can advance it only once the code under test is ready.
+func (c *FakeClock) BlockUntil(n int) {
+	c.timers.blockUntil(n)
+}
+
+// Slept returns the durations Sleep advanced the clock by, in order, when WithAutoAdvance is set.
+func (c *FakeClock) Slept() []time.Duration {
+	return c.timers.sleeps()
+}
+
+// Next returns the earliest deadline of the waiting timers, tickers and sleepers.
+func (c *FakeClock) Next() (time.Time, bool) {
+	return c.timers.next()
+}
+
+// WaitForTimers waits until the AfterFunc callbacks fired so far have returned.
+// Callbacks handed to an Executor are not waited for.
+func (c *FakeClock) WaitForTimers() {
+	c.timers.waitForFuncs()
+}
+
+type fakeTimers struct {
+	mutex   sync.Mutex
+	waiters *sync.Cond
+	now     time.Time
+	queue   fakeTimerQueue
+	seq     uint64
+	running int // AfterFunc callbacks that have not returned yet
+	slept   []time.Duration
+
+	executor    Executor
+	autoAdvance bool
+}
+
+func newFakeTimers(now time.Time) *fakeTimers {
+	q := &fakeTimers{now: now}
+	q.waiters = sync.NewCond(&q.mutex)
+	return q
+}
+
+func (q *fakeTimers) blockUntil(n int) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for len(q.queue) < n {
+		q.waiters.Wait()
+	}
+}
+
+func (q *fakeTimers) waitForFuncs() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for q.running > 0 {
+		q.waiters.Wait()
+	}
+}
+
+func (q *fakeTimers) current() time.Time {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return q.now
+}
+
+// next returns the earliest deadline of the waiting timers.
+func (q *fakeTimers) next() (time.Time, bool) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if len(q.queue) == 0 {
+		return time.Time{}, false
+	}
+	return q.queue[0].when, true
+}
+
+func (q *fakeTimers) set(now time.Time) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if now.Before(q.now) {
+		q.now = now
+		return
+	}
+	q.advanceLocked(now)
+}
+
+func (q *fakeTimers) sleep(d time.Duration) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.slept = append(q.slept, d)
+	q.advanceLocked(q.now.Add(d))
+}
+
+func (q *fakeTimers) sleeps() []time.Duration {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	return slices.Clone(q.slept)
+}
+
+func (q *fakeTimers) advance(d time.Duration) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.advanceLocked(q.now.Add(d))
+}
+
+func (q *fakeTimers) advanceTo(now time.Time) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.advanceLocked(now)
+}
+
+// advanceLocked fires the due timers in deadline order, each one seeing the clock at its own deadline.
+func (q *fakeTimers) advanceLocked(now time.Time) {
+	for len(q.queue) > 0 && !q.queue[0].when.After(now) {
+		t := q.queue[0]
+		if q.now.Before(t.when) {
+			q.now = t.when
+		}
+
+		if t.period > 0 {
+			t.when = t.when.Add(t.period)
+			t.seq = q.nextSeq()
+			heap.Fix(&q.queue, 0)
+		} else {
+			heap.Pop(&q.queue)
+		}
+		q.fire(t)
+	}
+
+	if q.now.Before(now) {
+		q.now = now
+	}
+}
+
+func (q *fakeTimers) fire(t *fakeTimer) {
+	if t.f != nil {
+		if q.executor != nil {
+			q.executor.Go(t.f)
+			return
+		}
+		q.running++
+		go func() {
+			defer q.funcDone()
+			t.f()
+		}()
+		return
+	}
+
+	// Like the time package, a tick is dropped when the previous one has not been received yet.
+	select {
+	case t.ch <- q.now:
+	default:
+	}
+}
+
+func (q *fakeTimers) funcDone() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.running--
+	q.waiters.Broadcast()
+}
+
+func (q *fakeTimers) nextSeq() uint64 {
+	q.seq++
+	return q.seq
+}
+
+func (q *fakeTimers) newTimer(d time.Duration, f func()) *fakeTimer {
+	t := &fakeTimer{timers: q, f: f, index: -1}
+	if f == nil {
+		t.ch = make(chan time.Time, 1)
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.schedule(t, d)
+	return t
+}
+
+func (q *fakeTimers) newTicker(d time.Duration) *fakeTicker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+
+	t := &fakeTimer{timers: q, ch: make(chan time.Time, 1), period: d, index: -1}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.schedule(t, d)
+	return &fakeTicker{t}
+}
+
+func (q *fakeTimers) schedule(t *fakeTimer, d time.Duration) {
+	if d <= 0 && t.period == 0 {
+		q.fire(t)
+		return
+	}
+
+	t.when = q.now.Add(d)
+	t.seq = q.nextSeq()
+	heap.Push(&q.queue, t)
+	q.waiters.Broadcast()
+}
+
+func (q *fakeTimers) stop(t *fakeTimer) bool {
+	if t.index < 0 {
+		return false
+	}
+	heap.Remove(&q.queue, t.index)
+	return true
+}
+
+type fakeTimer struct {
+	timers *fakeTimers
+	ch     chan time.Time
+	f      func()
+	when   time.Time
+	period time.Duration
+	seq    uint64
+	index  int
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.timers.mutex.Lock()
+	defer t.timers.mutex.Unlock()
+	return t.timers.stop(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.timers.mutex.Lock()
+	defer t.timers.mutex.Unlock()
+
+	active := t.timers.stop(t)
+	t.timers.schedule(t, d)
+	return active
+}
+
+type fakeTicker struct {
+	timer *fakeTimer
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.timer.ch
+}
+
+func (t *fakeTicker) Stop() {
+	t.timer.Stop()
+}
+
+func (t *fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for Ticker.Reset")
+	}
+
+	t.timer.timers.mutex.Lock()
+	defer t.timer.timers.mutex.Unlock()
+
+	t.timer.timers.stop(t.timer)
+	t.timer.period = d
+	t.timer.timers.schedule(t.timer, d)
+}
+
+type fakeTimerQueue []*fakeTimer
+
+func (q fakeTimerQueue) Len() int { return len(q) }
+
+func (q fakeTimerQueue) Less(i, j int) bool {
+	if q[i].when.Equal(q[j].when) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].when.Before(q[j].when)
+}
+
+func (q fakeTimerQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *fakeTimerQueue) Push(x any) {
+	t := x.(*fakeTimer)
+	t.index = len(*q)
+	*q = append(*q, t)
+}
+
+func (q *fakeTimerQueue) Pop() any {
+	old := *q
+	n := len(old)
+	t := old[n-1]
+	old[n-1] = nil
+	t.index = -1
+	*q = old[:n-1]
+	return t
+}
@@ -0,0 +1,123 @@
+package clock
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FakeClock Test", func() {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	It("fires timers in deadline order", func() {
+		c := NewFake(start)
+		t2 := c.NewTimer(2 * time.Second)
+		t1 := c.NewTimer(1 * time.Second)
+		t3 := c.NewTimer(3 * time.Second)
+
+		c.Advance(1500 * time.Millisecond)
+		Expect(t1.C()).To(Receive(Equal(start.Add(1 * time.Second))))
+		Expect(t2.C()).NotTo(Receive())
+
+		c.Advance(2 * time.Second)
+		Expect(t2.C()).To(Receive(Equal(start.Add(2 * time.Second))))
+		Expect(t3.C()).To(Receive(Equal(start.Add(3 * time.Second))))
+		Expect(c.Now()).To(Equal(start.Add(3500 * time.Millisecond)))
+	})
+
+	It("stops and resets timers", func() {
+		c := NewFake(start)
+		t := c.NewTimer(time.Second)
+		Expect(t.Stop()).To(BeTrue())
+		Expect(t.Stop()).To(BeFalse())
+
+		c.Advance(time.Second)
+		Expect(t.C()).NotTo(Receive())
+
+		Expect(t.Reset(time.Second)).To(BeFalse())
+		c.Advance(time.Second)
+		Expect(t.C()).To(Receive(Equal(start.Add(2 * time.Second))))
+	})
+
+	It("ticks and drops unreceived ticks", func() {
+		c := NewFake(start)
+		ticker := c.NewTicker(time.Second)
+
+		c.Advance(time.Second)
+		Expect(ticker.C()).To(Receive(Equal(start.Add(1 * time.Second))))
+
+		c.Advance(3 * time.Second)
+		Expect(ticker.C()).To(Receive(Equal(start.Add(2 * time.Second))))
+		Expect(ticker.C()).NotTo(Receive())
+
+		ticker.Stop()
+		c.Advance(time.Second)
+		Expect(ticker.C()).NotTo(Receive())
+	})
+
+	It("runs AfterFunc and wakes sleepers when advanced", func() {
+		c := NewFake(start)
+		fired := false
+		c.AfterFunc(time.Second, func() { fired = true })
+
+		done := make(chan error)
+		go func() { done <- c.Sleep(context.Background(), time.Minute) }()
+		c.BlockUntil(2)
+
+		c.Advance(time.Second)
+		c.WaitForTimers()
+		Expect(fired).To(BeTrue())
+		Consistently(done, 50*time.Millisecond).ShouldNot(Receive())
+
+		c.Advance(time.Minute)
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("can be advanced from several goroutines", func() {
+		c := NewFake(start)
+		ticker := c.NewTicker(time.Second)
+		wg := &sync.WaitGroup{}
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					c.Advance(100 * time.Millisecond)
+					c.Now()
+				}
+			}()
+		}
+		wg.Wait()
+
+		Expect(c.Now()).To(Equal(start.Add(80 * time.Second)))
+		Expect(ticker.C()).To(Receive(Equal(start.Add(time.Second))))
+	})
+
+	It("moves backwards with Set without firing", func() {
+		c := NewFake(start)
+		t := c.NewTimer(time.Second)
+		c.Set(start.Add(-time.Hour))
+		Expect(t.C()).NotTo(Receive())
+
+		c.Set(start.Add(time.Second))
+		Expect(t.C()).To(Receive(Equal(start.Add(time.Second))))
+	})
+
+	It("advances on Sleep with auto advance", func() {
+		c := NewFake(start, WithAutoAdvance())
+		Expect(c.Sleep(context.Background(), time.Second)).To(Succeed())
+		Expect(c.Sleep(context.Background(), time.Minute)).To(Succeed())
+		Expect(c.Now()).To(Equal(start.Add(time.Minute + time.Second)))
+		Expect(c.Slept()).To(Equal([]time.Duration{time.Second, time.Minute}))
+	})
+
+	It("aborts Sleep when the context is canceled", func() {
+		c := NewFake(start)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Expect(c.Sleep(ctx, time.Second)).To(MatchError(context.Canceled))
+	})
+})
@@ -0,0 +1,154 @@
+package clock
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var ErrClockDrift = errors.New("remote timestamp is too far ahead")
+
+// Timestamp is a hybrid logical clock reading: the wall time in Unix nanoseconds
+// and a counter ordering events that share the same wall time.
+type Timestamp struct {
+	WallTime int64
+	Logical  uint32
+}
+
+func (t Timestamp) Compare(o Timestamp) int {
+	switch {
+	case t.WallTime < o.WallTime:
+		return -1
+	case t.WallTime > o.WallTime:
+		return 1
+	case t.Logical < o.Logical:
+		return -1
+	case t.Logical > o.Logical:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (t Timestamp) Less(o Timestamp) bool {
+	return t.Compare(o) < 0
+}
+
+func (t Timestamp) IsZero() bool {
+	return t == Timestamp{}
+}
+
+func (t Timestamp) Time() time.Time {
+	return time.Unix(0, t.WallTime)
+}
+
+// MarshalBinary encodes the timestamp in 12 bytes whose byte order matches Compare.
+func (t Timestamp) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 12)
+	binary.BigEndian.PutUint64(buf, uint64(t.WallTime)^1<<63)
+	binary.BigEndian.PutUint32(buf[8:], t.Logical)
+	return buf, nil
+}
+
+func (t *Timestamp) UnmarshalBinary(data []byte) error {
+	if len(data) != 12 {
+		return errors.Errorf("invalid timestamp: length %d", len(data))
+	}
+	t.WallTime = int64(binary.BigEndian.Uint64(data) ^ 1<<63)
+	t.Logical = binary.BigEndian.Uint32(data[8:])
+	return nil
+}
+
+// String formats the timestamp as "<wall time>.<logical>".
+func (t Timestamp) String() string {
+	return fmt.Sprintf("%d.%d", t.WallTime, t.Logical)
+}
+
+func (t Timestamp) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *Timestamp) UnmarshalText(text []byte) error {
+	parsed, err := ParseTimestamp(string(text))
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+func ParseTimestamp(s string) (Timestamp, error) {
+	wall, logical, ok := strings.Cut(s, ".")
+	if !ok {
+		return Timestamp{}, errors.Errorf("invalid timestamp %q", s)
+	}
+	w, err := strconv.ParseInt(wall, 10, 64)
+	if err != nil {
+		return Timestamp{}, errors.Wrapf(err, "invalid timestamp %q", s)
+	}
+	l, err := strconv.ParseUint(logical, 10, 32)
+	if err != nil {
+		return Timestamp{}, errors.Wrapf(err, "invalid timestamp %q", s)
+	}
+	return Timestamp{WallTime: w, Logical: uint32(l)}, nil
+}
+
+// HLC is a hybrid logical clock. Its timestamps never go backwards, follow every remote timestamp it has seen,
+// and stay close to the physical time of c.
+type HLC struct {
+	clock    Clock
+	maxDrift time.Duration
+	mutex    sync.Mutex
+	last     Timestamp
+}
+
+// NewHLC returns a hybrid logical clock. Update rejects remote timestamps more than maxDrift ahead of the
+// physical clock, so that one bad clock cannot drag every node into the future; 0 disables the check.
+func NewHLC(clock Clock, maxDrift time.Duration) *HLC {
+	return &HLC{clock: clock, maxDrift: maxDrift}
+}
+
+// Now returns a timestamp for a local or send event.
+func (h *HLC) Now() Timestamp {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	physical := h.clock.Now().UnixNano()
+	if physical > h.last.WallTime {
+		h.last = Timestamp{WallTime: physical}
+	} else {
+		h.last.Logical++
+	}
+	return h.last
+}
+
+// Update merges a timestamp received from another node, and returns a timestamp for the receive event
+// that is later than both.
+func (h *HLC) Update(remote Timestamp) (Timestamp, error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	physical := h.clock.Now().UnixNano()
+	if h.maxDrift > 0 && time.Duration(remote.WallTime-physical) > h.maxDrift {
+		return Timestamp{}, errors.Wrapf(ErrClockDrift, "%s ahead", time.Duration(remote.WallTime-physical))
+	}
+
+	wall := max(h.last.WallTime, remote.WallTime, physical)
+	var logical uint32
+	switch {
+	case wall == h.last.WallTime && wall == remote.WallTime:
+		logical = max(h.last.Logical, remote.Logical) + 1
+	case wall == h.last.WallTime:
+		logical = h.last.Logical + 1
+	case wall == remote.WallTime:
+		logical = remote.Logical + 1
+	}
+
+	h.last = Timestamp{WallTime: wall, Logical: logical}
+	return h.last, nil
+}
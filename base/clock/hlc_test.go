@@ -0,0 +1,132 @@
+package clock
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("HLC Test", func() {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	It("counts logically while the physical clock stands still", func() {
+		c := NewFake(start)
+		h := NewHLC(c, 0)
+
+		t1 := h.Now()
+		t2 := h.Now()
+		Expect(t1).To(Equal(Timestamp{WallTime: start.UnixNano()}))
+		Expect(t2).To(Equal(Timestamp{WallTime: start.UnixNano(), Logical: 1}))
+
+		c.Advance(time.Nanosecond)
+		Expect(h.Now()).To(Equal(Timestamp{WallTime: start.UnixNano() + 1}))
+	})
+
+	It("follows remote timestamps", func() {
+		c := NewFake(start)
+		h := NewHLC(c, time.Second)
+
+		remote := Timestamp{WallTime: start.Add(500 * time.Millisecond).UnixNano(), Logical: 7}
+		t, err := h.Update(remote)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t).To(Equal(Timestamp{WallTime: remote.WallTime, Logical: 8}))
+		Expect(h.Now()).To(Equal(Timestamp{WallTime: remote.WallTime, Logical: 9}))
+
+		_, err = h.Update(Timestamp{WallTime: start.Add(2 * time.Second).UnixNano()})
+		Expect(errors.Is(err, ErrClockDrift)).To(BeTrue())
+		Expect(h.Now()).To(Equal(Timestamp{WallTime: remote.WallTime, Logical: 10}))
+	})
+
+	It("encodes and compares", func() {
+		ts := []Timestamp{
+			{WallTime: -5, Logical: 3},
+			{WallTime: 0, Logical: 0},
+			{WallTime: 1, Logical: 0},
+			{WallTime: 1, Logical: 2},
+			{WallTime: start.UnixNano(), Logical: 1},
+		}
+		for i := range ts {
+			b, err := ts[i].MarshalBinary()
+			Expect(err).NotTo(HaveOccurred())
+			var decoded Timestamp
+			Expect(decoded.UnmarshalBinary(b)).To(Succeed())
+			Expect(decoded).To(Equal(ts[i]))
+
+			parsed, err := ParseTimestamp(ts[i].String())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed).To(Equal(ts[i]))
+
+			if i > 0 {
+				prev, _ := ts[i-1].MarshalBinary()
+				Expect(bytes.Compare(prev, b)).To(Equal(-1))
+				Expect(ts[i-1].Compare(ts[i])).To(Equal(-1))
+				Expect(ts[i].Compare(ts[i-1])).To(Equal(1))
+				Expect(ts[i-1].Less(ts[i])).To(BeTrue())
+			}
+		}
+
+		_, err := ParseTimestamp("12")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("preserves causality and monotonicity under clock skew", func() {
+		const maxSkew = 50 * time.Millisecond
+
+		for seed := uint64(0); seed < 20; seed++ {
+			rnd := rand.New(rand.NewPCG(seed, 0))
+
+			type node struct {
+				clock *FakeClock
+				hlc   *HLC
+				last  Timestamp
+			}
+			nodes := make([]*node, 4)
+			for i := range nodes {
+				c := NewFake(start.Add(time.Duration(rnd.Int64N(int64(2*maxSkew))) - maxSkew))
+				nodes[i] = &node{clock: c, hlc: NewHLC(c, 2*maxSkew)}
+			}
+
+			type message struct {
+				to int
+				ts Timestamp
+			}
+			var inflight []message
+
+			for step := 0; step < 500; step++ {
+				n := nodes[rnd.IntN(len(nodes))]
+				var ts Timestamp
+
+				switch p := rnd.IntN(10); {
+				case p < 3:
+					n.clock.Advance(time.Duration(rnd.Int64N(int64(time.Millisecond))))
+					continue
+				case p < 6:
+					ts = n.hlc.Now()
+					inflight = append(inflight, message{to: rnd.IntN(len(nodes)), ts: ts})
+				case len(inflight) > 0:
+					i := rnd.IntN(len(inflight))
+					m := inflight[i]
+					inflight = append(inflight[:i], inflight[i+1:]...)
+					n = nodes[m.to]
+
+					var err error
+					ts, err = n.hlc.Update(m.ts)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(m.ts.Less(ts)).To(BeTrue(), "receive must follow send")
+				default:
+					ts = n.hlc.Now()
+				}
+
+				Expect(n.last.Less(ts)).To(BeTrue(), "timestamps of a node must increase")
+				n.last = ts
+
+				drift := time.Duration(ts.WallTime - n.clock.Now().UnixNano())
+				Expect(drift).To(BeNumerically("<=", 2*maxSkew), "timestamps must stay close to the physical clock")
+			}
+		}
+	})
+})
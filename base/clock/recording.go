@@ -0,0 +1,167 @@
+package clock
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Call is a call recorded by RecordingClock.
+type Call struct {
+	Method string
+	// Time is the time returned by Now. It is zero for the other methods, which do not read the clock.
+	Time time.Time
+	// Duration is the argument of Sleep, After, NewTimer, NewTicker and AfterFunc, or the result of Since and Until.
+	Duration time.Duration
+	Caller   string
+	Stack    string
+}
+
+func (c Call) String() string {
+	switch {
+	case c.Method == "Now":
+		return fmt.Sprintf("Now() = %s at %s", c.Time.Format(time.RFC3339Nano), c.Caller)
+	case c.Method == "Since" || c.Method == "Until":
+		return fmt.Sprintf("%s() = %s at %s", c.Method, c.Duration, c.Caller)
+	default:
+		return fmt.Sprintf("%s(%s) at %s", c.Method, c.Duration, c.Caller)
+	}
+}
+
+// TB is the part of testing.TB used by RecordingClock, also implemented by GinkgoT().
+type TB interface {
+	Helper()
+	Cleanup(f func())
+	Failed() bool
+	Logf(format string, args ...any)
+}
+
+// RecordingClock wraps a clock and records every call, to find out who read the time and when.
+type RecordingClock struct {
+	clock Clock
+	stack bool
+	mutex sync.Mutex
+	calls []Call
+}
+
+var _ Clock = (*RecordingClock)(nil)
+
+// NewRecording wraps c. If stack is true, the full stack of every call is recorded as well as its caller.
+func NewRecording(c Clock, stack bool) *RecordingClock {
+	return &RecordingClock{clock: c, stack: stack}
+}
+
+func (c *RecordingClock) Now() time.Time {
+	now := c.clock.Now()
+	c.record(Call{Method: "Now", Time: now})
+	return now
+}
+
+func (c *RecordingClock) Since(t time.Time) time.Duration {
+	d := c.clock.Since(t)
+	c.record(Call{Method: "Since", Duration: d})
+	return d
+}
+
+func (c *RecordingClock) Until(t time.Time) time.Duration {
+	d := c.clock.Until(t)
+	c.record(Call{Method: "Until", Duration: d})
+	return d
+}
+
+func (c *RecordingClock) Sleep(ctx context.Context, d time.Duration) error {
+	c.record(Call{Method: "Sleep", Duration: d})
+	return c.clock.Sleep(ctx, d)
+}
+
+func (c *RecordingClock) After(d time.Duration) <-chan time.Time {
+	c.record(Call{Method: "After", Duration: d})
+	return c.clock.After(d)
+}
+
+func (c *RecordingClock) NewTimer(d time.Duration) Timer {
+	c.record(Call{Method: "NewTimer", Duration: d})
+	return c.clock.NewTimer(d)
+}
+
+func (c *RecordingClock) NewTicker(d time.Duration) Ticker {
+	c.record(Call{Method: "NewTicker", Duration: d})
+	return c.clock.NewTicker(d)
+}
+
+func (c *RecordingClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.record(Call{Method: "AfterFunc", Duration: d})
+	return c.clock.AfterFunc(d, f)
+}
+
+func (c *RecordingClock) Calls() []Call {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]Call(nil), c.calls...)
+}
+
+// NowCalls returns the times returned by Now, in order.
+func (c *RecordingClock) NowCalls() []time.Time {
+	var times []time.Time
+	for _, call := range c.Calls() {
+		if call.Method == "Now" {
+			times = append(times, call.Time)
+		}
+	}
+	return times
+}
+
+func (c *RecordingClock) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = nil
+}
+
+// Trace formats the recorded calls, one per line, with their stacks if recorded.
+func (c *RecordingClock) Trace() string {
+	b := &strings.Builder{}
+	for i, call := range c.Calls() {
+		fmt.Fprintf(b, "#%d %s\n", i, call)
+		if call.Stack != "" {
+			for _, line := range strings.Split(strings.TrimRight(call.Stack, "\n"), "\n") {
+				fmt.Fprintf(b, "    %s\n", line)
+			}
+		}
+	}
+	return b.String()
+}
+
+// LogOnFailure logs the trace when the test fails.
+func (c *RecordingClock) LogOnFailure(t TB) {
+	t.Helper()
+	t.Cleanup(func() {
+		if t.Failed() {
+			t.Logf("clock calls:\n%s", c.Trace())
+		}
+	})
+}
+
+func (c *RecordingClock) record(call Call) {
+	// 0 is record, 1 is the RecordingClock method, 2 is its caller.
+	if pc, file, line, ok := runtime.Caller(2); ok {
+		call.Caller = fmt.Sprintf("%s (%s:%d)", funcName(pc), file, line)
+	}
+	if c.stack {
+		call.Stack = string(debug.Stack())
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = append(c.calls, call)
+}
+
+func funcName(pc uintptr) string {
+	if f := runtime.FuncForPC(pc); f != nil {
+		return f.Name()
+	}
+	return "?"
+}
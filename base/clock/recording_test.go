@@ -0,0 +1,83 @@
+package clock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type tbMock struct {
+	failed   bool
+	cleanups []func()
+	logs     []string
+}
+
+func (t *tbMock) Helper() {}
+
+func (t *tbMock) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func (t *tbMock) Failed() bool {
+	return t.failed
+}
+
+func (t *tbMock) Logf(format string, args ...any) {
+	t.logs = append(t.logs, fmt.Sprintf(format, args...))
+}
+
+func readTwice(c Clock) {
+	c.Now()
+	c.Now()
+}
+
+var _ = Describe("RecordingClock Test", func() {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	It("records calls with their caller", func() {
+		base := NewFake(start)
+		c := NewRecording(base, false)
+
+		readTwice(c)
+		base.Advance(10 * time.Second)
+		Expect(c.Since(start)).To(Equal(10 * time.Second))
+		Expect(c.Sleep(context.Background(), 0)).To(Succeed())
+		c.NewTimer(time.Minute).Stop()
+
+		calls := c.Calls()
+		Expect(calls).To(HaveLen(5))
+		Expect(calls[0].Caller).To(ContainSubstring("clock.readTwice"))
+		Expect(calls[0].Caller).To(ContainSubstring("recording_test.go"))
+		Expect(calls[0].Stack).To(BeEmpty())
+		Expect(calls[2].Method).To(Equal("Since"))
+		Expect(calls[2].Duration).To(Equal(10 * time.Second))
+		Expect(calls[4].Method).To(Equal("NewTimer"))
+		Expect(c.NowCalls()).To(Equal([]time.Time{start, start}))
+
+		c.Reset()
+		Expect(c.Calls()).To(BeEmpty())
+	})
+
+	It("records stacks and logs the trace on failure", func() {
+		c := NewRecording(NewFake(start), true)
+		readTwice(c)
+
+		trace := c.Trace()
+		Expect(trace).To(ContainSubstring("#0 Now() = 2000-01-01T00:00:00Z at "))
+		Expect(trace).To(ContainSubstring("#1 Now()"))
+		Expect(trace).To(ContainSubstring("    goroutine "))
+
+		t := &tbMock{}
+		c.LogOnFailure(t)
+		t.cleanups[0]()
+		Expect(t.logs).To(BeEmpty())
+
+		t.failed = true
+		t.cleanups[0]()
+		Expect(t.logs).To(HaveLen(1))
+		Expect(t.logs[0]).To(ContainSubstring("clock.readTwice"))
+	})
+})
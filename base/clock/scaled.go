@@ -0,0 +1,180 @@
+package clock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ScaledClock runs factor times faster than its base clock, starting from start.
+// Timers and tickers are measured in scaled time, so a one-minute ticker on a clock scaled by 60 ticks every second.
+type ScaledClock struct {
+	base   Clock
+	factor float64
+	timers *fakeTimers
+
+	mutex      sync.Mutex
+	anchorBase time.Time
+	anchorNow  time.Time
+	paused     bool
+	alarm      Timer
+}
+
+var _ Clock = (*ScaledClock)(nil)
+
+func NewScaled(base Clock, start time.Time, factor float64) *ScaledClock {
+	if factor <= 0 {
+		panic("non-positive factor for NewScaled")
+	}
+	return &ScaledClock{
+		base:       base,
+		factor:     factor,
+		timers:     newFakeTimers(start),
+		anchorBase: base.Now(),
+		anchorNow:  start,
+	}
+}
+
+func (c *ScaledClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.nowLocked()
+}
+
+func (c *ScaledClock) nowLocked() time.Time {
+	if c.paused {
+		return c.anchorNow
+	}
+	elapsed := c.base.Now().Sub(c.anchorBase)
+	return c.anchorNow.Add(time.Duration(float64(elapsed) * c.factor))
+}
+
+func (c *ScaledClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
+
+func (c *ScaledClock) Until(t time.Time) time.Duration {
+	return t.Sub(c.Now())
+}
+
+func (c *ScaledClock) Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := c.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C():
+		return nil
+	}
+}
+
+func (c *ScaledClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+func (c *ScaledClock) NewTimer(d time.Duration) Timer {
+	c.sync()
+	t := &scaledTimer{fakeTimer: c.timers.newTimer(d, nil), clock: c}
+	c.rearm()
+	return t
+}
+
+func (c *ScaledClock) NewTicker(d time.Duration) Ticker {
+	c.sync()
+	t := &scaledTicker{fakeTicker: c.timers.newTicker(d), clock: c}
+	c.rearm()
+	return t
+}
+
+func (c *ScaledClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.sync()
+	t := &scaledTimer{fakeTimer: c.timers.newTimer(d, f), clock: c}
+	c.rearm()
+	return t
+}
+
+// Pause stops the scaled time, and with it all timers, until Resume is called.
+func (c *ScaledClock) Pause() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.paused {
+		return
+	}
+	c.anchorNow = c.nowLocked()
+	c.paused = true
+	c.stopAlarm()
+}
+
+func (c *ScaledClock) Resume() {
+	c.mutex.Lock()
+	if !c.paused {
+		c.mutex.Unlock()
+		return
+	}
+	c.anchorBase = c.base.Now()
+	c.paused = false
+	c.mutex.Unlock()
+
+	c.rearm()
+}
+
+// sync fires the timers that are due, and lets new timers count from the current scaled time.
+func (c *ScaledClock) sync() {
+	c.timers.advanceTo(c.Now())
+}
+
+// rearm sets an alarm on the base clock for the earliest timer.
+func (c *ScaledClock) rearm() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.stopAlarm()
+	if c.paused {
+		return
+	}
+	next, ok := c.timers.next()
+	if !ok {
+		return
+	}
+	d := time.Duration(float64(next.Sub(c.nowLocked())) / c.factor)
+	c.alarm = c.base.AfterFunc(max(d, 0), func() {
+		c.sync()
+		c.rearm()
+	})
+}
+
+func (c *ScaledClock) stopAlarm() {
+	if c.alarm != nil {
+		c.alarm.Stop()
+		c.alarm = nil
+	}
+}
+
+type scaledTimer struct {
+	*fakeTimer
+	clock *ScaledClock
+}
+
+func (t *scaledTimer) Reset(d time.Duration) bool {
+	t.clock.sync()
+	active := t.fakeTimer.Reset(d)
+	t.clock.rearm()
+	return active
+}
+
+type scaledTicker struct {
+	*fakeTicker
+	clock *ScaledClock
+}
+
+func (t *scaledTicker) Reset(d time.Duration) {
+	t.clock.sync()
+	t.fakeTicker.Reset(d)
+	t.clock.rearm()
+}
@@ -0,0 +1,78 @@
+package clock
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ScaledClock Test", func() {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	replay := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+	It("runs faster than the base clock from the start time", func() {
+		base := NewFake(start)
+		c := NewScaled(base, replay, 10)
+		Expect(c.Now()).To(Equal(replay))
+
+		base.Advance(time.Second)
+		Expect(c.Now()).To(Equal(replay.Add(10 * time.Second)))
+		Expect(c.Since(replay)).To(Equal(10 * time.Second))
+	})
+
+	It("scales timers and tickers", func() {
+		base := NewFake(start)
+		c := NewScaled(base, replay, 60)
+		timer := c.NewTimer(time.Minute)
+		ticker := c.NewTicker(30 * time.Second)
+
+		base.Advance(500 * time.Millisecond)
+		Eventually(ticker.C()).Should(Receive(Equal(replay.Add(30 * time.Second))))
+		Consistently(timer.C(), 50*time.Millisecond).ShouldNot(Receive())
+
+		base.Advance(500 * time.Millisecond)
+		Eventually(timer.C()).Should(Receive(Equal(replay.Add(time.Minute))))
+		Eventually(ticker.C()).Should(Receive(Equal(replay.Add(time.Minute))))
+		ticker.Stop()
+	})
+
+	It("pauses and resumes", func() {
+		base := NewFake(start)
+		c := NewScaled(base, replay, 10)
+		fired := make(chan struct{})
+		c.AfterFunc(20*time.Second, func() { close(fired) })
+
+		base.Advance(time.Second)
+		c.Pause()
+		base.Advance(time.Hour)
+		Expect(c.Now()).To(Equal(replay.Add(10 * time.Second)))
+		Consistently(fired, 50*time.Millisecond).ShouldNot(BeClosed())
+
+		c.Resume()
+		base.Advance(500 * time.Millisecond)
+		Expect(c.Now()).To(Equal(replay.Add(15 * time.Second)))
+		Consistently(fired, 50*time.Millisecond).ShouldNot(BeClosed())
+		base.Advance(500 * time.Millisecond)
+		Eventually(fired).Should(BeClosed())
+	})
+
+	It("sleeps in scaled time on the real clock", func() {
+		c := NewScaled(New(), replay, 1000)
+		begin := time.Now()
+		Expect(c.Sleep(context.Background(), 10*time.Second)).To(Succeed())
+		Expect(time.Since(begin)).To(BeNumerically("<", time.Second))
+		Expect(c.Since(replay)).To(BeNumerically(">=", 10*time.Second))
+	})
+
+	It("resets timers in scaled time", func() {
+		base := NewFake(start)
+		c := NewScaled(base, replay, 10)
+		timer := c.NewTimer(time.Hour)
+		Expect(timer.Reset(10 * time.Second)).To(BeTrue())
+
+		base.Advance(time.Second)
+		Eventually(timer.C()).Should(Receive(Equal(replay.Add(10 * time.Second))))
+	})
+})
@@ -0,0 +1,50 @@
+package id
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrClockRegression is returned when the clock went back further than WithMaxRegression allows.
+	ErrClockRegression = errors.New("clock moved backwards")
+	// ErrOverflow is returned when too many IDs are generated within a single millisecond.
+	ErrOverflow = errors.New("too many ids in one millisecond")
+)
+
+type Option func(*options)
+
+type options struct {
+	maxRegression time.Duration
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		maxRegression: -1,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithMaxRegression makes the generators fail with ErrClockRegression when the clock goes back by more than d.
+// By default they keep counting from the last timestamp until the clock catches up.
+func WithMaxRegression(d time.Duration) Option {
+	return func(o *options) {
+		o.maxRegression = d
+	}
+}
+
+// timestamp returns the millisecond to use for the next id, which never goes below last.
+func (o *options) timestamp(now time.Time, last int64) (int64, error) {
+	ms := now.UnixMilli()
+	if ms >= last {
+		return ms, nil
+	}
+	if o.maxRegression >= 0 && time.Duration(last-ms)*time.Millisecond > o.maxRegression {
+		return 0, errors.Wrapf(ErrClockRegression, "by %s", time.Duration(last-ms)*time.Millisecond)
+	}
+	return last, nil
+}
@@ -0,0 +1,13 @@
+package id
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestID(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ID Spec")
+}
@@ -0,0 +1,135 @@
+package id
+
+import (
+	"database/sql/driver"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	"github.com/pkg/errors"
+)
+
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+	snowflakeMaxNode      = 1<<snowflakeNodeBits - 1
+	snowflakeMaxSequence  = 1<<snowflakeSequenceBits - 1
+)
+
+// Snowflake is a 41-bit millisecond timestamp since an epoch, a 10-bit node and a 12-bit sequence.
+type Snowflake int64
+
+type SnowflakeGenerator struct {
+	clock    clock.Clock
+	epoch    int64
+	node     int64
+	options  *options
+	mutex    sync.Mutex
+	last     int64
+	seen     int64 // the latest clock reading, which last runs ahead of after a millisecond ran out
+	sequence int64
+}
+
+// NewSnowflakeGenerator returns a generator for node, which must be unique among the running generators.
+// When the 4096 sequence numbers of a millisecond run out, it moves on to the next millisecond
+// instead of waiting for it.
+func NewSnowflakeGenerator(clock clock.Clock, epoch time.Time, node int64, opts ...Option) (*SnowflakeGenerator, error) {
+	if node < 0 || node > snowflakeMaxNode {
+		return nil, errors.Errorf("snowflake node %d out of range [0, %d]", node, snowflakeMaxNode)
+	}
+	return &SnowflakeGenerator{
+		clock:   clock,
+		epoch:   epoch.UnixMilli(),
+		node:    node,
+		options: newOptions(opts),
+		last:    -1,
+		seen:    -1,
+	}, nil
+}
+
+func (g *SnowflakeGenerator) New() (Snowflake, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	now := g.clock.Now().UnixMilli() - g.epoch
+	if now < 0 {
+		return 0, errors.New("clock is before the snowflake epoch")
+	}
+	// Regressions are measured against the clock, not against a millisecond borrowed from the future.
+	seen, err := g.options.timestamp(time.UnixMilli(now), g.seen)
+	if err != nil {
+		return 0, err
+	}
+	g.seen = seen
+	ms := max(seen, g.last)
+
+	if ms == g.last {
+		g.sequence++
+		if g.sequence > snowflakeMaxSequence {
+			ms++
+			g.sequence = 0
+		}
+	} else {
+		g.sequence = 0
+	}
+	if ms >= 1<<41 {
+		return 0, ErrOverflow
+	}
+
+	g.last = ms
+	return Snowflake(ms<<(snowflakeNodeBits+snowflakeSequenceBits) | g.node<<snowflakeSequenceBits | g.sequence), nil
+}
+
+func ParseSnowflake(s string) (Snowflake, error) {
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || v < 0 {
+		return 0, errors.Errorf("invalid snowflake %q", s)
+	}
+	return Snowflake(v), nil
+}
+
+// Time returns the timestamp, given the epoch of the generator that made the id.
+func (s Snowflake) Time(epoch time.Time) time.Time {
+	return time.UnixMilli(epoch.UnixMilli() + int64(s)>>(snowflakeNodeBits+snowflakeSequenceBits))
+}
+
+func (s Snowflake) Node() int64 {
+	return int64(s) >> snowflakeSequenceBits & snowflakeMaxNode
+}
+
+func (s Snowflake) Sequence() int64 {
+	return int64(s) & snowflakeMaxSequence
+}
+
+func (s Snowflake) String() string {
+	return strconv.FormatInt(int64(s), 10)
+}
+
+func (s *Snowflake) Scan(src any) error {
+	switch v := src.(type) {
+	case int64:
+		*s = Snowflake(v)
+		return nil
+	case []byte:
+		parsed, err := ParseSnowflake(string(v))
+		if err != nil {
+			return err
+		}
+		*s = parsed
+		return nil
+	case string:
+		parsed, err := ParseSnowflake(v)
+		if err != nil {
+			return err
+		}
+		*s = parsed
+		return nil
+	default:
+		return errors.Errorf("cannot scan %T into Snowflake", src)
+	}
+}
+
+func (s Snowflake) Value() (driver.Value, error) {
+	return int64(s), nil
+}
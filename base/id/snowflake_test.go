@@ -0,0 +1,105 @@
+package id
+
+import (
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("Snowflake Test", func() {
+	epoch := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	start := epoch.Add(24 * time.Hour)
+
+	It("packs timestamp, node and sequence", func() {
+		c := clock.NewFake(start)
+		g, err := NewSnowflakeGenerator(c, epoch, 5)
+		Expect(err).NotTo(HaveOccurred())
+
+		first, err := g.New()
+		Expect(err).NotTo(HaveOccurred())
+		second, err := g.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(first.Time(epoch)).To(BeTemporally("==", start))
+		Expect(first.Node()).To(Equal(int64(5)))
+		Expect(first.Sequence()).To(Equal(int64(0)))
+		Expect(second.Sequence()).To(Equal(int64(1)))
+
+		parsed, err := ParseSnowflake(second.String())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed).To(Equal(second))
+	})
+
+	It("moves to the next millisecond when the sequence runs out", func() {
+		c := clock.NewFake(start)
+		g, err := NewSnowflakeGenerator(c, epoch, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		var last Snowflake
+		for i := 0; i <= snowflakeMaxSequence+1; i++ {
+			id, err := g.New()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).To(BeNumerically(">", last))
+			last = id
+		}
+		Expect(last.Time(epoch)).To(BeTemporally("==", start.Add(time.Millisecond)))
+		Expect(last.Sequence()).To(Equal(int64(0)))
+	})
+
+	It("handles clock regression", func() {
+		c := clock.NewFake(start)
+		g, err := NewSnowflakeGenerator(c, epoch, 0, WithMaxRegression(time.Second))
+		Expect(err).NotTo(HaveOccurred())
+
+		first, err := g.New()
+		Expect(err).NotTo(HaveOccurred())
+		c.Set(c.Now().Add(-500 * time.Millisecond))
+		second, err := g.New()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second).To(BeNumerically(">", first))
+
+		c.Set(c.Now().Add(-time.Second))
+		_, err = g.New()
+		Expect(errors.Is(err, ErrClockRegression)).To(BeTrue())
+	})
+
+	It("does not mistake a borrowed millisecond for a regression", func() {
+		c := clock.NewFake(start)
+		g, err := NewSnowflakeGenerator(c, epoch, 0, WithMaxRegression(0))
+		Expect(err).NotTo(HaveOccurred())
+
+		var last Snowflake
+		for i := 0; i < 4097; i++ {
+			id, err := g.New()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id).To(BeNumerically(">", last))
+			last = id
+		}
+		Expect(last.Time(epoch)).To(BeTemporally("==", start.Add(time.Millisecond)))
+
+		id, err := g.New()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id).To(BeNumerically(">", last))
+	})
+
+	It("validates the node", func() {
+		_, err := NewSnowflakeGenerator(clock.NewFake(start), epoch, 1024)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("scans and values", func() {
+		var s Snowflake
+		Expect(s.Scan(int64(42))).To(Succeed())
+		Expect(s).To(Equal(Snowflake(42)))
+		Expect(s.Scan([]byte("43"))).To(Succeed())
+		Expect(s).To(Equal(Snowflake(43)))
+		Expect(s.Scan("x")).To(HaveOccurred())
+
+		v, err := s.Value()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal(int64(43)))
+	})
+})
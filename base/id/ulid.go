@@ -0,0 +1,172 @@
+package id
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/binary"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	"github.com/pkg/errors"
+)
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID is a 48-bit millisecond timestamp followed by 80 random bits.
+// Its 26-character Crockford base32 form sorts in the same order as the bytes.
+type ULID [16]byte
+
+type ULIDGenerator struct {
+	clock   clock.Clock
+	entropy io.Reader
+	options *options
+	mutex   sync.Mutex
+	last    ULID
+}
+
+// NewULIDGenerator returns a generator reading randomness from entropy, or crypto/rand if it is nil.
+// IDs generated within the same millisecond increment the random part of the previous one, so they stay sorted.
+func NewULIDGenerator(clock clock.Clock, entropy io.Reader, opts ...Option) *ULIDGenerator {
+	if entropy == nil {
+		entropy = rand.Reader
+	}
+	return &ULIDGenerator{clock: clock, entropy: entropy, options: newOptions(opts)}
+}
+
+func (g *ULIDGenerator) New() (ULID, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	lastMs := g.last.millis()
+	ms, err := g.options.timestamp(g.clock.Now(), lastMs)
+	if err != nil {
+		return ULID{}, err
+	}
+
+	var id ULID
+	if ms == lastMs && g.last != (ULID{}) {
+		id = g.last
+		if !increment(id[6:]) {
+			return ULID{}, ErrOverflow
+		}
+	} else {
+		putMillis(id[:6], ms)
+		if _, err := io.ReadFull(g.entropy, id[6:]); err != nil {
+			return ULID{}, errors.Wrap(err, "read entropy")
+		}
+	}
+
+	g.last = id
+	return id, nil
+}
+
+func ParseULID(s string) (ULID, error) {
+	var id ULID
+	if err := id.UnmarshalText([]byte(s)); err != nil {
+		return ULID{}, err
+	}
+	return id, nil
+}
+
+func (id ULID) Time() time.Time {
+	return time.UnixMilli(id.millis())
+}
+
+func (id ULID) millis() int64 {
+	return int64(binary.BigEndian.Uint64(append([]byte{0, 0}, id[:6]...)))
+}
+
+func (id ULID) String() string {
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+
+	var buf [26]byte
+	for i := len(buf) - 1; i >= 0; i-- {
+		buf[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(buf[:])
+}
+
+func (id ULID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+func (id *ULID) UnmarshalText(text []byte) error {
+	if len(text) != 26 {
+		return errors.Errorf("invalid ulid %q: length must be 26", text)
+	}
+
+	var hi, lo uint64
+	for i, c := range text {
+		v := crockfordValue(c)
+		if v < 0 || (i == 0 && v > 7) {
+			return errors.Errorf("invalid ulid %q", text)
+		}
+		hi = hi<<5 | lo>>59
+		lo = lo<<5 | uint64(v)
+	}
+
+	binary.BigEndian.PutUint64(id[:8], hi)
+	binary.BigEndian.PutUint64(id[8:], lo)
+	return nil
+}
+
+func crockfordValue(c byte) int {
+	if c >= 'a' && c <= 'z' {
+		c -= 'a' - 'A'
+	}
+	switch c {
+	case 'O':
+		return 0
+	case 'I', 'L':
+		return 1
+	}
+	for i := 0; i < len(crockford); i++ {
+		if crockford[i] == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// Scan accepts the 26-character text form, or the 16 raw bytes as stored in a BINARY(16) column.
+func (id *ULID) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return id.UnmarshalText([]byte(v))
+	case []byte:
+		if len(v) == len(id) {
+			copy(id[:], v)
+			return nil
+		}
+		return id.UnmarshalText(v)
+	default:
+		return errors.Errorf("cannot scan %T into ULID", src)
+	}
+}
+
+// Value stores the text form, which keeps the sort order in CHAR(26) columns.
+func (id ULID) Value() (driver.Value, error) {
+	return id.String(), nil
+}
+
+func putMillis(b []byte, ms int64) {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(ms))
+	copy(b, buf[2:])
+}
+
+// increment adds one to the big-endian number in b, and reports false when it overflows.
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package id
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("ULID Test", func() {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	It("encodes the timestamp and entropy", func() {
+		c := clock.NewFake(start)
+		g := NewULIDGenerator(c, bytes.NewReader(bytes.Repeat([]byte{0xff}, 10)))
+
+		id, err := g.New()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id.Time()).To(BeTemporally("==", start))
+		Expect(id.String()).To(HaveLen(26))
+		Expect(id.String()).To(HaveSuffix("ZZZZZZZZZZZZZZZZ"))
+
+		parsed, err := ParseULID(strings.ToLower(id.String()))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parsed).To(Equal(id))
+	})
+
+	It("stays monotonic within a millisecond and across a clock regression", func() {
+		c := clock.NewFake(start)
+		g := NewULIDGenerator(c, nil)
+
+		var ids []string
+		for i := 0; i < 100; i++ {
+			if i == 50 {
+				c.Set(c.Now().Add(-time.Second))
+			}
+			id, err := g.New()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(id.Time()).To(BeTemporally("==", start))
+			ids = append(ids, id.String())
+		}
+		Expect(sort.StringsAreSorted(ids)).To(BeTrue())
+
+		c.Advance(2 * time.Second)
+		id, err := g.New()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id.Time()).To(BeTemporally("==", start.Add(time.Second)))
+	})
+
+	It("fails on regression beyond the limit", func() {
+		c := clock.NewFake(start)
+		g := NewULIDGenerator(c, nil, WithMaxRegression(10*time.Millisecond))
+		_, err := g.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		c.Set(c.Now().Add(-5 * time.Millisecond))
+		_, err = g.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		c.Set(c.Now().Add(-10 * time.Millisecond))
+		_, err = g.New()
+		Expect(errors.Is(err, ErrClockRegression)).To(BeTrue())
+	})
+
+	It("overflows the random part", func() {
+		c := clock.NewFake(start)
+		g := NewULIDGenerator(c, bytes.NewReader(bytes.Repeat([]byte{0xff}, 10)))
+		_, err := g.New()
+		Expect(err).NotTo(HaveOccurred())
+		_, err = g.New()
+		Expect(err).To(MatchError(ErrOverflow))
+	})
+
+	It("rejects invalid text", func() {
+		_, err := ParseULID("8ZZZZZZZZZZZZZZZZZZZZZZZZZ")
+		Expect(err).To(HaveOccurred())
+		_, err = ParseULID("0000")
+		Expect(err).To(HaveOccurred())
+		_, err = ParseULID("0000000000000000000000000U")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("scans and values", func() {
+		id, err := NewULIDGenerator(clock.NewFake(start), nil).New()
+		Expect(err).NotTo(HaveOccurred())
+
+		v, err := id.Value()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal(driver.Value(id.String())))
+
+		var fromText, fromBinary ULID
+		Expect(fromText.Scan(v)).To(Succeed())
+		Expect(fromBinary.Scan(id[:])).To(Succeed())
+		Expect(fromText).To(Equal(id))
+		Expect(fromBinary).To(Equal(id))
+		Expect(fromText.Scan(42)).To(HaveOccurred())
+	})
+})
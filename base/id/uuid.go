@@ -0,0 +1,173 @@
+package id
+
+import (
+	"crypto/rand"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	"github.com/pkg/errors"
+)
+
+// UUID is an RFC 9562 UUID. The generator produces version 7: a 48-bit millisecond timestamp,
+// then 74 random bits around the version and variant.
+type UUID [16]byte
+
+type UUIDGenerator struct {
+	clock   clock.Clock
+	entropy io.Reader
+	options *options
+	mutex   sync.Mutex
+	last    UUID
+}
+
+// NewUUIDGenerator returns a version 7 generator reading randomness from entropy, or crypto/rand if it is nil.
+// UUIDs generated within the same millisecond increment the random bits of the previous one, so they stay sorted.
+func NewUUIDGenerator(clock clock.Clock, entropy io.Reader, opts ...Option) *UUIDGenerator {
+	if entropy == nil {
+		entropy = rand.Reader
+	}
+	return &UUIDGenerator{clock: clock, entropy: entropy, options: newOptions(opts)}
+}
+
+func (g *UUIDGenerator) New() (UUID, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	lastMs := g.last.millis()
+	ms, err := g.options.timestamp(g.clock.Now(), lastMs)
+	if err != nil {
+		return UUID{}, err
+	}
+
+	var id UUID
+	if ms == lastMs && g.last != (UUID{}) {
+		id = g.last
+		if !id.incrementRandom() {
+			return UUID{}, ErrOverflow
+		}
+	} else {
+		putMillis(id[:6], ms)
+		if _, err := io.ReadFull(g.entropy, id[6:]); err != nil {
+			return UUID{}, errors.Wrap(err, "read entropy")
+		}
+		id[6] = 0x70 | id[6]&0x0f
+		id[8] = 0x80 | id[8]&0x3f
+	}
+
+	g.last = id
+	return id, nil
+}
+
+// incrementRandom treats the 12 bits of rand_a and the 62 bits of rand_b as one counter.
+func (id *UUID) incrementRandom() bool {
+	randB := binary.BigEndian.Uint64(id[8:]) & (1<<62 - 1)
+	randA := binary.BigEndian.Uint16(id[6:8]) & 0x0fff
+
+	randB = (randB + 1) & (1<<62 - 1)
+	if randB == 0 {
+		randA = (randA + 1) & 0x0fff
+		if randA == 0 {
+			return false
+		}
+	}
+
+	binary.BigEndian.PutUint16(id[6:8], 0x7000|randA)
+	binary.BigEndian.PutUint64(id[8:], 1<<63|randB)
+	return true
+}
+
+func ParseUUID(s string) (UUID, error) {
+	var id UUID
+	if err := id.UnmarshalText([]byte(s)); err != nil {
+		return UUID{}, err
+	}
+	return id, nil
+}
+
+func (id UUID) Version() int {
+	return int(id[6] >> 4)
+}
+
+// Time returns the timestamp of a version 7 UUID, and the zero time for other versions.
+func (id UUID) Time() time.Time {
+	if id.Version() != 7 {
+		return time.Time{}
+	}
+	return time.UnixMilli(id.millis())
+}
+
+func (id UUID) millis() int64 {
+	return int64(binary.BigEndian.Uint64(append([]byte{0, 0}, id[:6]...)))
+}
+
+func (id UUID) String() string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], id[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], id[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], id[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], id[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], id[10:])
+	return string(buf[:])
+}
+
+func (id UUID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText accepts the hyphenated form and the 32-digit form without hyphens.
+func (id *UUID) UnmarshalText(text []byte) error {
+	var digits []byte
+	switch len(text) {
+	case 36:
+		if text[8] != '-' || text[13] != '-' || text[18] != '-' || text[23] != '-' {
+			return errors.Errorf("invalid uuid %q", text)
+		}
+		digits = make([]byte, 0, 32)
+		digits = append(digits, text[0:8]...)
+		digits = append(digits, text[9:13]...)
+		digits = append(digits, text[14:18]...)
+		digits = append(digits, text[19:23]...)
+		digits = append(digits, text[24:]...)
+	case 32:
+		digits = text
+	default:
+		return errors.Errorf("invalid uuid %q: unexpected length", text)
+	}
+
+	var parsed UUID
+	if _, err := hex.Decode(parsed[:], digits); err != nil {
+		return errors.Wrapf(err, "invalid uuid %q", text)
+	}
+	*id = parsed
+	return nil
+}
+
+// Scan accepts the text form, or the 16 raw bytes read from a binary column.
+func (id *UUID) Scan(src any) error {
+	switch v := src.(type) {
+	case string:
+		return id.UnmarshalText([]byte(v))
+	case []byte:
+		if len(v) == len(id) {
+			copy(id[:], v)
+			return nil
+		}
+		return id.UnmarshalText(v)
+	default:
+		return errors.Errorf("cannot scan %T into UUID", src)
+	}
+}
+
+// Value stores the text form, for CHAR(36) and native uuid columns. For a BINARY(16) column, pass id[:] instead.
+func (id UUID) Value() (driver.Value, error) {
+	return id.String(), nil
+}
@@ -0,0 +1,78 @@
+package id
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"strings"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UUID Test", func() {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	It("sets version, variant and timestamp", func() {
+		g := NewUUIDGenerator(clock.NewFake(start), bytes.NewReader(bytes.Repeat([]byte{0xff}, 10)))
+		id, err := g.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(id.Version()).To(Equal(7))
+		Expect(id[8] >> 6).To(Equal(byte(2)))
+		Expect(id.Time()).To(BeTemporally("==", start))
+		Expect(id.String()).To(Equal("00dc6acf-ac00-7fff-bfff-ffffffffffff"))
+	})
+
+	It("stays monotonic within a millisecond", func() {
+		c := clock.NewFake(start)
+		g := NewUUIDGenerator(c, bytes.NewReader(append(bytes.Repeat([]byte{0}, 2), bytes.Repeat([]byte{0xff}, 8)...)))
+
+		first, err := g.New()
+		Expect(err).NotTo(HaveOccurred())
+		second, err := g.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(second.String() > first.String()).To(BeTrue())
+		Expect(second.Version()).To(Equal(7))
+		Expect(second[8] >> 6).To(Equal(byte(2)))
+		Expect(strings.Split(second.String(), "-")[2]).To(Equal("7001"))
+	})
+
+	It("parses both forms", func() {
+		id, err := ParseUUID("017F22E2-79B0-7CC3-98C4-DC0C0C07398F")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id.String()).To(Equal("017f22e2-79b0-7cc3-98c4-dc0c0c07398f"))
+		Expect(id.Time()).To(BeTemporally("==", time.UnixMilli(0x017f22e279b0)))
+
+		compact, err := ParseUUID("017f22e279b07cc398c4dc0c0c07398f")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(compact).To(Equal(id))
+
+		_, err = ParseUUID("017f22e2_79b0_7cc3_98c4_dc0c0c07398f")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("has no time for other versions", func() {
+		id, err := ParseUUID("f47ac10b-58cc-4372-a567-0e02b2c3d479")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(id.Version()).To(Equal(4))
+		Expect(id.Time().IsZero()).To(BeTrue())
+	})
+
+	It("scans and values", func() {
+		id, err := NewUUIDGenerator(clock.NewFake(start), nil).New()
+		Expect(err).NotTo(HaveOccurred())
+
+		v, err := id.Value()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal(driver.Value(id.String())))
+
+		var fromText, fromBinary UUID
+		Expect(fromText.Scan(v)).To(Succeed())
+		Expect(fromBinary.Scan(id[:])).To(Succeed())
+		Expect(fromText).To(Equal(id))
+		Expect(fromBinary).To(Equal(id))
+	})
+})
@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+)
+
+// KeyedLimiter keeps a separate Limiter per key, e.g. per user or per cache key.
+// It holds at most capacity limiters and drops the ones not used for idleTimeout,
+// so idleTimeout should be long enough for a limiter to have fully recovered.
+type KeyedLimiter[K comparable] struct {
+	clock       clock.Clock
+	capacity    int
+	idleTimeout time.Duration
+	newLimiter  func() Limiter
+	mutex       sync.Mutex
+	entries     map[K]*list.Element
+	lru         *list.List
+}
+
+type keyedEntry[K comparable] struct {
+	key      K
+	limiter  Limiter
+	lastUsed time.Time
+}
+
+func NewKeyedLimiter[K comparable](clock clock.Clock, capacity int, idleTimeout time.Duration, newLimiter func() Limiter) *KeyedLimiter[K] {
+	return &KeyedLimiter[K]{
+		clock:       clock,
+		capacity:    capacity,
+		idleTimeout: idleTimeout,
+		newLimiter:  newLimiter,
+		entries:     make(map[K]*list.Element),
+		lru:         list.New(),
+	}
+}
+
+func (k *KeyedLimiter[K]) Allow(key K) bool {
+	return k.get(key).Allow()
+}
+
+func (k *KeyedLimiter[K]) Reserve(key K) *Reservation {
+	return k.get(key).Reserve()
+}
+
+func (k *KeyedLimiter[K]) Wait(ctx context.Context, key K) error {
+	return k.get(key).Wait(ctx)
+}
+
+func (k *KeyedLimiter[K]) Len() int {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	k.evictIdle(k.clock.Now())
+	return k.lru.Len()
+}
+
+func (k *KeyedLimiter[K]) get(key K) Limiter {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	now := k.clock.Now()
+	k.evictIdle(now)
+
+	if elem, ok := k.entries[key]; ok {
+		e := elem.Value.(*keyedEntry[K])
+		e.lastUsed = now
+		k.lru.MoveToFront(elem)
+		return e.limiter
+	}
+
+	for k.capacity > 0 && k.lru.Len() >= k.capacity {
+		k.remove(k.lru.Back())
+	}
+	e := &keyedEntry[K]{key: key, limiter: k.newLimiter(), lastUsed: now}
+	k.entries[key] = k.lru.PushFront(e)
+	return e.limiter
+}
+
+func (k *KeyedLimiter[K]) evictIdle(now time.Time) {
+	if k.idleTimeout <= 0 {
+		return
+	}
+	for elem := k.lru.Back(); elem != nil; elem = k.lru.Back() {
+		if now.Sub(elem.Value.(*keyedEntry[K]).lastUsed) < k.idleTimeout {
+			return
+		}
+		k.remove(elem)
+	}
+}
+
+func (k *KeyedLimiter[K]) remove(elem *list.Element) {
+	k.lru.Remove(elem)
+	delete(k.entries, elem.Value.(*keyedEntry[K]).key)
+}
@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("KeyedLimiter Test", func() {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	It("limits each key separately", func() {
+		c := clock.NewFake(start)
+		k := NewKeyedLimiter[string](c, 10, time.Minute, func() Limiter {
+			return NewTokenBucket(c, 1, 1)
+		})
+
+		Expect(k.Allow("a")).To(BeTrue())
+		Expect(k.Allow("a")).To(BeFalse())
+		Expect(k.Allow("b")).To(BeTrue())
+		Expect(k.Len()).To(Equal(2))
+	})
+
+	It("evicts the least recently used key over capacity", func() {
+		c := clock.NewFake(start)
+		k := NewKeyedLimiter[string](c, 2, time.Minute, func() Limiter {
+			return NewTokenBucket(c, 1, 1)
+		})
+
+		Expect(k.Allow("a")).To(BeTrue())
+		Expect(k.Allow("b")).To(BeTrue())
+		Expect(k.Allow("a")).To(BeFalse())
+		Expect(k.Allow("c")).To(BeTrue())
+		Expect(k.Len()).To(Equal(2))
+
+		// "b" was evicted, so it starts over with a full bucket.
+		Expect(k.Allow("b")).To(BeTrue())
+		Expect(k.Allow("c")).To(BeFalse())
+	})
+
+	It("drops idle keys", func() {
+		c := clock.NewFake(start)
+		k := NewKeyedLimiter[int](c, 10, time.Minute, func() Limiter {
+			return NewSlidingWindow(c, 1, time.Second)
+		})
+
+		Expect(k.Allow(1)).To(BeTrue())
+		c.Advance(30 * time.Second)
+		Expect(k.Allow(2)).To(BeTrue())
+		c.Advance(30 * time.Second)
+		Expect(k.Len()).To(Equal(1))
+		c.Advance(30 * time.Second)
+		Expect(k.Len()).To(Equal(0))
+	})
+})
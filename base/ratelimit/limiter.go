@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	"github.com/pkg/errors"
+)
+
+// InfDuration is the delay of a reservation that can never be satisfied.
+const InfDuration = time.Duration(math.MaxInt64)
+
+var ErrNeverAllowed = errors.New("rate limit can never be satisfied")
+
+type Limiter interface {
+	// Allow reports whether an event may happen now, and consumes the permit if so.
+	Allow() bool
+	// Reserve takes a permit that becomes usable after Delay.
+	Reserve() *Reservation
+	// Wait blocks until an event may happen or ctx is done.
+	Wait(ctx context.Context) error
+}
+
+type Reservation struct {
+	ok        bool
+	clock     clock.Clock
+	timeToAct time.Time
+	cancel    func(now time.Time)
+}
+
+func (r *Reservation) OK() bool {
+	return r.ok
+}
+
+func (r *Reservation) Delay() time.Duration {
+	if !r.ok {
+		return InfDuration
+	}
+	return max(0, r.timeToAct.Sub(r.clock.Now()))
+}
+
+// Cancel gives the permit back if it has not been used yet.
+func (r *Reservation) Cancel() {
+	if !r.ok || r.cancel == nil {
+		return
+	}
+	r.cancel(r.clock.Now())
+	r.cancel = nil
+}
+
+func wait(ctx context.Context, c clock.Clock, r *Reservation) error {
+	if !r.ok {
+		return ErrNeverAllowed
+	}
+
+	delay := r.Delay()
+	if delay == 0 {
+		return nil
+	}
+	// The deadline is compared with c, so it should come from clock.WithDeadline when c is not the real clock.
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(c.Now().Add(delay)) {
+		r.Cancel()
+		return errors.Errorf("rate limit wait of %s would exceed the context deadline", delay)
+	}
+	if err := c.Sleep(ctx, delay); err != nil {
+		r.Cancel()
+		return err
+	}
+	return nil
+}
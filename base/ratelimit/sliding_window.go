@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+)
+
+var _ Limiter = (*SlidingWindow)(nil)
+
+// SlidingWindow allows at most limit events in any window of the given length.
+// It remembers the time of the last limit events, so it is exact but uses memory proportional to limit.
+type SlidingWindow struct {
+	clock  clock.Clock
+	window time.Duration
+	mutex  sync.Mutex
+	slots  []time.Time
+	owners []uint64 // the reservation that took each slot, so that a cancel only clears its own
+	seq    uint64
+	head   int
+}
+
+func NewSlidingWindow(clock clock.Clock, limit int, window time.Duration) *SlidingWindow {
+	return &SlidingWindow{
+		clock:  clock,
+		window: window,
+		slots:  make([]time.Time, max(0, limit)),
+		owners: make([]uint64, max(0, limit)),
+	}
+}
+
+func (w *SlidingWindow) Allow() bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if len(w.slots) == 0 {
+		return false
+	}
+	now := w.clock.Now()
+	if w.free(w.head).After(now) {
+		return false
+	}
+	w.take(now)
+	return true
+}
+
+func (w *SlidingWindow) Reserve() *Reservation {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if len(w.slots) == 0 {
+		return &Reservation{clock: w.clock}
+	}
+
+	now := w.clock.Now()
+	timeToAct := now
+	if free := w.free(w.head); free.After(now) {
+		timeToAct = free
+	}
+	slot, owner := w.take(timeToAct)
+
+	return &Reservation{
+		ok:        true,
+		clock:     w.clock,
+		timeToAct: timeToAct,
+		cancel: func(now time.Time) {
+			if !now.Before(timeToAct) {
+				return
+			}
+			w.mutex.Lock()
+			defer w.mutex.Unlock()
+			// The slot may have been reused by a later reservation in the meantime, possibly for the same time.
+			if w.owners[slot] == owner {
+				w.slots[slot] = time.Time{}
+			}
+		},
+	}
+}
+
+func (w *SlidingWindow) Wait(ctx context.Context) error {
+	return wait(ctx, w.clock, w.Reserve())
+}
+
+// free returns when the slot leaves the window. Unused slots are free from the start.
+func (w *SlidingWindow) free(slot int) time.Time {
+	if w.slots[slot].IsZero() {
+		return time.Time{}
+	}
+	return w.slots[slot].Add(w.window)
+}
+
+func (w *SlidingWindow) take(at time.Time) (int, uint64) {
+	slot := w.head
+	w.seq++
+	w.slots[slot] = at
+	w.owners[slot] = w.seq
+	w.head = (w.head + 1) % len(w.slots)
+	return slot, w.seq
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SlidingWindow Test", func() {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	It("allows limit events per window", func() {
+		c := clock.NewFake(start)
+		w := NewSlidingWindow(c, 2, time.Minute)
+
+		Expect(w.Allow()).To(BeTrue())
+		c.Advance(30 * time.Second)
+		Expect(w.Allow()).To(BeTrue())
+		Expect(w.Allow()).To(BeFalse())
+
+		c.Advance(30 * time.Second)
+		Expect(w.Allow()).To(BeTrue())
+		Expect(w.Allow()).To(BeFalse())
+
+		c.Advance(30 * time.Second)
+		Expect(w.Allow()).To(BeTrue())
+	})
+
+	It("reserves the next free slot", func() {
+		c := clock.NewFake(start)
+		w := NewSlidingWindow(c, 2, time.Minute)
+
+		Expect(w.Allow()).To(BeTrue())
+		c.Advance(10 * time.Second)
+		Expect(w.Allow()).To(BeTrue())
+
+		r1 := w.Reserve()
+		Expect(r1.Delay()).To(Equal(50 * time.Second))
+		r2 := w.Reserve()
+		Expect(r2.Delay()).To(Equal(time.Minute))
+
+		r2.Cancel()
+		r1.Cancel()
+		c.Advance(50 * time.Second)
+		Expect(w.Allow()).To(BeTrue())
+		Expect(w.Allow()).To(BeTrue())
+		Expect(w.Allow()).To(BeFalse())
+	})
+
+	It("does not free a slot reused for the same time on cancel", func() {
+		c := clock.NewFake(start.Add(time.Second))
+		w := NewSlidingWindow(c, 1, 0)
+		Expect(w.Allow()).To(BeTrue())
+
+		c.Set(start)
+		r1 := w.Reserve()
+		r2 := w.Reserve()
+		Expect(r1.Delay()).To(Equal(time.Second))
+		Expect(r2.Delay()).To(Equal(time.Second))
+
+		r1.Cancel()
+		Expect(w.Allow()).To(BeFalse())
+		r2.Cancel()
+		Expect(w.Allow()).To(BeTrue())
+	})
+
+	It("never allows with zero limit", func() {
+		w := NewSlidingWindow(clock.NewFake(start), 0, time.Minute)
+		Expect(w.Allow()).To(BeFalse())
+		Expect(w.Reserve().OK()).To(BeFalse())
+	})
+})
@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+)
+
+var _ Limiter = (*TokenBucket)(nil)
+
+// TokenBucket allows bursts of up to burst events, refilled at rate tokens per second.
+type TokenBucket struct {
+	clock  clock.Clock
+	rate   float64
+	burst  int
+	mutex  sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func NewTokenBucket(clock clock.Clock, rate float64, burst int) *TokenBucket {
+	return &TokenBucket{
+		clock:  clock,
+		rate:   rate,
+		burst:  burst,
+		tokens: float64(burst),
+		last:   clock.Now(),
+	}
+}
+
+func (b *TokenBucket) Allow() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := b.clock.Now()
+	b.refill(now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (b *TokenBucket) Reserve() *Reservation {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := b.clock.Now()
+	b.refill(now)
+	if b.burst < 1 || (b.tokens < 1 && b.rate <= 0) {
+		return &Reservation{clock: b.clock}
+	}
+
+	b.tokens--
+	timeToAct := now
+	if b.tokens < 0 {
+		timeToAct = now.Add(time.Duration(-b.tokens / b.rate * float64(time.Second)))
+	}
+
+	return &Reservation{
+		ok:        true,
+		clock:     b.clock,
+		timeToAct: timeToAct,
+		cancel: func(now time.Time) {
+			if !now.Before(timeToAct) {
+				return
+			}
+			b.mutex.Lock()
+			defer b.mutex.Unlock()
+			b.refill(now)
+			b.tokens = min(b.tokens+1, float64(b.burst))
+		},
+	}
+}
+
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	return wait(ctx, b.clock, b.Reserve())
+}
+
+// Tokens returns the number of tokens available now. It is negative while reservations are pending.
+func (b *TokenBucket) Tokens() float64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.refill(b.clock.Now())
+	return b.tokens
+}
+
+func (b *TokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.tokens+elapsed.Seconds()*b.rate, float64(b.burst))
+		b.last = now
+	}
+}
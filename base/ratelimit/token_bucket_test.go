@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TokenBucket Test", func() {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	It("allows bursts and refills", func() {
+		c := clock.NewFake(start)
+		b := NewTokenBucket(c, 2, 3)
+
+		Expect(b.Allow()).To(BeTrue())
+		Expect(b.Allow()).To(BeTrue())
+		Expect(b.Allow()).To(BeTrue())
+		Expect(b.Allow()).To(BeFalse())
+
+		c.Advance(500 * time.Millisecond)
+		Expect(b.Allow()).To(BeTrue())
+		Expect(b.Allow()).To(BeFalse())
+
+		c.Advance(time.Hour)
+		Expect(b.Tokens()).To(Equal(3.0))
+	})
+
+	It("reserves ahead and gives back canceled reservations", func() {
+		c := clock.NewFake(start)
+		b := NewTokenBucket(c, 2, 1)
+
+		Expect(b.Reserve().Delay()).To(Equal(time.Duration(0)))
+		r1 := b.Reserve()
+		Expect(r1.Delay()).To(Equal(500 * time.Millisecond))
+		r2 := b.Reserve()
+		Expect(r2.Delay()).To(Equal(time.Second))
+
+		r2.Cancel()
+		Expect(b.Tokens()).To(Equal(-1.0))
+
+		c.Advance(500 * time.Millisecond)
+		r1.Cancel()
+		Expect(b.Tokens()).To(Equal(0.0))
+	})
+
+	It("never allows without burst", func() {
+		b := NewTokenBucket(clock.NewFake(start), 1, 0)
+		r := b.Reserve()
+		Expect(r.OK()).To(BeFalse())
+		Expect(r.Delay()).To(Equal(InfDuration))
+		Expect(b.Wait(context.Background())).To(MatchError(ErrNeverAllowed))
+	})
+
+	It("waits on the clock", func() {
+		c := clock.NewFake(start)
+		b := NewTokenBucket(c, 1, 1)
+		Expect(b.Allow()).To(BeTrue())
+
+		done := make(chan error)
+		go func() { done <- b.Wait(context.Background()) }()
+		c.BlockUntil(1)
+		c.Advance(999 * time.Millisecond)
+		Consistently(done, 50*time.Millisecond).ShouldNot(Receive())
+		c.Advance(time.Millisecond)
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("gives the token back when Wait is canceled", func() {
+		c := clock.NewFake(start)
+		b := NewTokenBucket(c, 1, 1)
+		Expect(b.Allow()).To(BeTrue())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- b.Wait(ctx) }()
+		c.BlockUntil(1)
+		cancel()
+		Eventually(done).Should(Receive(MatchError(context.Canceled)))
+		Expect(b.Tokens()).To(Equal(0.0))
+	})
+
+	It("fails fast when the deadline is too close", func() {
+		c := clock.NewFake(start)
+		b := NewTokenBucket(c, 1, 1)
+		Expect(b.Allow()).To(BeTrue())
+
+		ctx, cancel := clock.WithTimeout(context.Background(), c, 500*time.Millisecond)
+		defer cancel()
+		Expect(b.Wait(ctx)).To(HaveOccurred())
+		Expect(b.Tokens()).To(Equal(0.0))
+	})
+})
@@ -0,0 +1,65 @@
+package retry
+
+import (
+	"math"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+)
+
+// Backoff returns the delay before the next attempt, after attempt attempts have failed.
+// prev is the previous delay, or 0 before the first retry.
+type Backoff interface {
+	Next(attempt int, prev time.Duration, r clock.Rand) time.Duration
+}
+
+// Exponential multiplies the delay by Multiplier after every attempt, up to Max,
+// and spreads each delay by ±Jitter (0 to 1).
+type Exponential struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	Jitter     float64
+}
+
+func (b *Exponential) Next(attempt int, _ time.Duration, r clock.Rand) time.Duration {
+	multiplier := math.Max(b.Multiplier, 1)
+	delay := float64(b.Initial) * math.Pow(multiplier, float64(attempt-1))
+	if b.Max > 0 {
+		delay = math.Min(delay, float64(b.Max))
+	}
+	if b.Jitter > 0 {
+		delay *= 1 + b.Jitter*(2*r.Float64()-1)
+	}
+	return toDuration(delay)
+}
+
+// DecorrelatedJitter picks each delay at random between Base and three times the previous delay, up to Max.
+// It spreads retries of many clients better than Exponential with jitter.
+type DecorrelatedJitter struct {
+	Base time.Duration
+	Max  time.Duration
+}
+
+func (b *DecorrelatedJitter) Next(_ int, prev time.Duration, r clock.Rand) time.Duration {
+	prev = max(prev, b.Base)
+	delay := float64(b.Base) + r.Float64()*(3*float64(prev)-float64(b.Base))
+	if b.Max > 0 {
+		delay = math.Min(delay, float64(b.Max))
+	}
+	return toDuration(delay)
+}
+
+// toDuration converts delay, which grows without bound when Max is 0, saturating instead of overflowing.
+func toDuration(delay float64) time.Duration {
+	if delay >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(delay)
+}
+
+type Constant time.Duration
+
+func (b Constant) Next(int, time.Duration, clock.Rand) time.Duration {
+	return time.Duration(b)
+}
@@ -0,0 +1,56 @@
+package retry
+
+import (
+	"math"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type randMock struct {
+	data []float64
+}
+
+func (r *randMock) Float64() float64 {
+	v := r.data[0]
+	r.data = r.data[1:]
+	return v
+}
+
+var _ = Describe("Backoff Test", func() {
+	It("exponential", func() {
+		b := &Exponential{Initial: 100 * time.Millisecond, Max: 300 * time.Millisecond, Multiplier: 2}
+		Expect(b.Next(1, 0, nil)).To(Equal(100 * time.Millisecond))
+		Expect(b.Next(2, 0, nil)).To(Equal(200 * time.Millisecond))
+		Expect(b.Next(3, 0, nil)).To(Equal(300 * time.Millisecond))
+	})
+
+	It("exponential with jitter", func() {
+		b := &Exponential{Initial: 100 * time.Millisecond, Multiplier: 2, Jitter: 0.5}
+		r := &randMock{data: []float64{0, 1}}
+		Expect(b.Next(1, 0, r)).To(Equal(50 * time.Millisecond))
+		Expect(b.Next(2, 0, r)).To(Equal(300 * time.Millisecond))
+	})
+
+	It("decorrelated jitter", func() {
+		b := &DecorrelatedJitter{Base: 100 * time.Millisecond, Max: time.Second}
+		r := &randMock{data: []float64{0, 1, 1}}
+		Expect(b.Next(1, 0, r)).To(Equal(100 * time.Millisecond))
+		Expect(b.Next(2, 100*time.Millisecond, r)).To(Equal(300 * time.Millisecond))
+		Expect(b.Next(3, 500*time.Millisecond, r)).To(Equal(time.Second))
+	})
+
+	It("saturates without a max", func() {
+		e := &Exponential{Initial: time.Second, Multiplier: 2}
+		Expect(e.Next(1000, 0, nil)).To(Equal(time.Duration(math.MaxInt64)))
+		Expect(e.Next(100000, 0, nil)).To(Equal(time.Duration(math.MaxInt64)))
+
+		d := &DecorrelatedJitter{Base: time.Second}
+		Expect(d.Next(1000, math.MaxInt64, &randMock{data: []float64{1}})).To(Equal(time.Duration(math.MaxInt64)))
+	})
+
+	It("constant", func() {
+		Expect(Constant(time.Second).Next(5, time.Minute, nil)).To(Equal(time.Second))
+	})
+})
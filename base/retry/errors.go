@@ -0,0 +1,67 @@
+package retry
+
+import (
+	"github.com/pkg/errors"
+)
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks err as not worth retrying. The mark is still found after err is wrapped with pkg/errors.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// RetryOn returns a classifier that retries only errors matching one of targets, even when they are wrapped.
+func RetryOn(targets ...error) func(err error) bool {
+	return func(err error) bool {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// StopOn returns a classifier that retries every error except those matching one of targets.
+func StopOn(targets ...error) func(err error) bool {
+	retryOn := RetryOn(targets...)
+	return func(err error) bool {
+		return !retryOn(err)
+	}
+}
+
+func retryable(classifier func(err error) bool, err error) bool {
+	if IsPermanent(err) {
+		return false
+	}
+	if classifier == nil {
+		return true
+	}
+	return classifier(err)
+}
+
+func unwrapPermanent(err error) error {
+	if p, ok := err.(*permanentError); ok {
+		return p.err
+	}
+	return err
+}
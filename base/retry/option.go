@@ -0,0 +1,68 @@
+package retry
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+)
+
+type Option func(*options)
+
+type options struct {
+	maxAttempts int
+	maxElapsed  time.Duration
+	classifier  func(err error) bool
+	onRetry     func(attempt int, err error, delay time.Duration)
+	rand        clock.Rand
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		rand: defaultRand{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithMaxAttempts limits the number of calls, including the first one. 0 means no limit.
+func WithMaxAttempts(n int) Option {
+	return func(o *options) {
+		o.maxAttempts = n
+	}
+}
+
+// WithMaxElapsed gives up instead of sleeping past d after the first call started. 0 means no limit.
+func WithMaxElapsed(d time.Duration) Option {
+	return func(o *options) {
+		o.maxElapsed = d
+	}
+}
+
+// WithClassifier decides which errors are retried. Permanent errors never are.
+func WithClassifier(f func(err error) bool) Option {
+	return func(o *options) {
+		o.classifier = f
+	}
+}
+
+// WithOnRetry is called before sleeping for a retry, with the number of failed attempts so far.
+func WithOnRetry(f func(attempt int, err error, delay time.Duration)) Option {
+	return func(o *options) {
+		o.onRetry = f
+	}
+}
+
+func WithRand(r clock.Rand) Option {
+	return func(o *options) {
+		o.rand = r
+	}
+}
+
+type defaultRand struct{}
+
+func (defaultRand) Float64() float64 {
+	return rand.Float64()
+}
@@ -0,0 +1,69 @@
+package retry
+
+import (
+	"context"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	"github.com/pkg/errors"
+)
+
+type Retrier struct {
+	clock   clock.Clock
+	backoff Backoff
+	options *options
+}
+
+func New(clock clock.Clock, backoff Backoff, opts ...Option) *Retrier {
+	return &Retrier{
+		clock:   clock,
+		backoff: backoff,
+		options: newOptions(opts),
+	}
+}
+
+// Do calls fn until it succeeds, returns an error that is not retryable, a limit is reached or ctx is done,
+// and returns the last error. Errors marked with Permanent are returned unmarked.
+func (r *Retrier) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	_, err := DoValue(ctx, r, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+func DoValue[T any](ctx context.Context, r *Retrier, fn func(ctx context.Context) (T, error)) (T, error) {
+	start := r.clock.Now()
+	var delay time.Duration
+
+	for attempt := 1; ; attempt++ {
+		value, err := fn(ctx)
+		if err == nil {
+			return value, nil
+		}
+		if !r.shouldRetry(attempt, err) {
+			return *new(T), unwrapPermanent(err)
+		}
+
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return *new(T), errors.Wrapf(ctxErr, "retry aborted: %v", err)
+		}
+
+		delay = r.backoff.Next(attempt, delay, r.options.rand)
+		if r.options.maxElapsed > 0 && delay > r.options.maxElapsed-r.clock.Since(start) {
+			return *new(T), err
+		}
+		if r.options.onRetry != nil {
+			r.options.onRetry(attempt, err, delay)
+		}
+		if sleepErr := r.clock.Sleep(ctx, delay); sleepErr != nil {
+			return *new(T), errors.Wrapf(sleepErr, "retry aborted: %v", err)
+		}
+	}
+}
+
+func (r *Retrier) shouldRetry(attempt int, err error) bool {
+	if r.options.maxAttempts > 0 && attempt >= r.options.maxAttempts {
+		return false
+	}
+	return retryable(r.options.classifier, err)
+}
@@ -0,0 +1,134 @@
+package retry
+
+import (
+	"context"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+var _ = Describe("Retry Test", func() {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	errTransient := errors.New("transient")
+	errFatal := errors.New("fatal")
+
+	It("retries until success", func() {
+		c := clock.NewFake(start, clock.WithAutoAdvance())
+		var retried []int
+		r := New(c, Constant(time.Second), WithMaxAttempts(5), WithOnRetry(func(attempt int, err error, delay time.Duration) {
+			Expect(err).To(MatchError(errTransient))
+			Expect(delay).To(Equal(time.Second))
+			retried = append(retried, attempt)
+		}))
+		calls := 0
+		err := r.Do(context.Background(), func(ctx context.Context) error {
+			calls++
+			if calls < 3 {
+				return errTransient
+			}
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(calls).To(Equal(3))
+		Expect(retried).To(Equal([]int{1, 2}))
+		Expect(c.Now()).To(Equal(start.Add(2 * time.Second)))
+	})
+
+	It("gives up after max attempts", func() {
+		c := clock.NewFake(start, clock.WithAutoAdvance())
+		r := New(c, &Exponential{Initial: time.Second, Multiplier: 2}, WithMaxAttempts(4))
+		calls := 0
+		err := r.Do(context.Background(), func(ctx context.Context) error {
+			calls++
+			return errors.Wrap(errTransient, "call")
+		})
+		Expect(err).To(MatchError(errTransient))
+		Expect(calls).To(Equal(4))
+		Expect(c.Slept()).To(Equal([]time.Duration{time.Second, 2 * time.Second, 4 * time.Second}))
+	})
+
+	It("gives up before exceeding max elapsed time", func() {
+		c := clock.NewFake(start, clock.WithAutoAdvance())
+		r := New(c, Constant(time.Second), WithMaxElapsed(2500*time.Millisecond))
+		calls := 0
+		err := r.Do(context.Background(), func(ctx context.Context) error {
+			calls++
+			return errTransient
+		})
+		Expect(err).To(MatchError(errTransient))
+		Expect(calls).To(Equal(3))
+		Expect(c.Slept()).To(HaveLen(2))
+	})
+
+	It("sees through pkg/errors wrapping", func() {
+		c := clock.NewFake(start)
+		r := New(c, Constant(time.Second), WithClassifier(StopOn(errFatal)))
+
+		calls := 0
+		err := r.Do(context.Background(), func(ctx context.Context) error {
+			calls++
+			return errors.Wrap(errors.WithMessage(errFatal, "inner"), "outer")
+		})
+		Expect(err).To(MatchError(errFatal))
+		Expect(calls).To(Equal(1))
+
+		calls = 0
+		err = r.Do(context.Background(), func(ctx context.Context) error {
+			calls++
+			return errors.Wrap(Permanent(errTransient), "outer")
+		})
+		Expect(err).To(MatchError(errTransient))
+		Expect(IsPermanent(err)).To(BeTrue())
+		Expect(calls).To(Equal(1))
+
+		err = r.Do(context.Background(), func(ctx context.Context) error {
+			return Permanent(errTransient)
+		})
+		Expect(err).To(Equal(errTransient))
+	})
+
+	It("retries only matching errors", func() {
+		classify := RetryOn(errTransient)
+		Expect(classify(errors.Wrap(errTransient, "x"))).To(BeTrue())
+		Expect(classify(errFatal)).To(BeFalse())
+	})
+
+	It("stops when the context is canceled", func() {
+		c := clock.NewFake(start)
+		r := New(c, Constant(time.Minute))
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		done := make(chan error)
+		go func() {
+			done <- r.Do(ctx, func(ctx context.Context) error {
+				calls++
+				return errTransient
+			})
+		}()
+
+		c.BlockUntil(1)
+		cancel()
+		var err error
+		Eventually(done).Should(Receive(&err))
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(err.Error()).To(ContainSubstring("transient"))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("returns the value", func() {
+		r := New(clock.NewFake(start), Constant(0))
+		calls := 0
+		v, err := DoValue(context.Background(), r, func(ctx context.Context) (int, error) {
+			calls++
+			if calls == 1 {
+				return 0, errTransient
+			}
+			return 42, nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal(42))
+	})
+})
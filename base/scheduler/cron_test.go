@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cron Test", func() {
+	at := func(s string) time.Time {
+		t, err := time.Parse(time.RFC3339, s)
+		Expect(err).NotTo(HaveOccurred())
+		return t
+	}
+
+	DescribeTable("next activation",
+		func(expr string, from string, want string) {
+			s, err := ParseCronInLocation(expr, time.UTC)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.Next(at(from))).To(BeTemporally("==", at(want)))
+		},
+		Entry("every minute", "* * * * *", "2000-01-01T00:00:30Z", "2000-01-01T00:01:00Z"),
+		Entry("exact match is excluded", "30 2 * * *", "2000-01-01T02:30:00Z", "2000-01-02T02:30:00Z"),
+		Entry("step", "*/15 * * * *", "2000-01-01T00:16:00Z", "2000-01-01T00:30:00Z"),
+		Entry("range with step", "0 9-17/4 * * *", "2000-01-01T10:00:00Z", "2000-01-01T13:00:00Z"),
+		Entry("list and names", "0 0 * JAN,MAR MON", "2000-01-01T00:00:00Z", "2000-01-03T00:00:00Z"),
+		Entry("sunday as 7", "0 0 * * 7", "2000-01-03T00:00:00Z", "2000-01-09T00:00:00Z"),
+		Entry("day of month or day of week", "0 0 15 * FRI", "2000-01-08T00:00:00Z", "2000-01-14T00:00:00Z"),
+		Entry("leap day", "0 0 29 2 *", "2001-01-01T00:00:00Z", "2004-02-29T00:00:00Z"),
+		Entry("seconds", "*/10 * * * * *", "2000-01-01T00:00:05Z", "2000-01-01T00:00:10Z"),
+		Entry("descriptor", "@monthly", "2000-01-15T00:00:00Z", "2000-02-01T00:00:00Z"),
+	)
+
+	It("uses the time zone", func() {
+		s, err := ParseCron("CRON_TZ=Asia/Tokyo 0 9 * * *")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Next(at("2000-01-01T00:00:00Z"))).To(BeTemporally("==", at("2000-01-02T00:00:00Z")))
+	})
+
+	It("skips times that do not exist", func() {
+		loc, err := time.LoadLocation("America/New_York")
+		Expect(err).NotTo(HaveOccurred())
+		s, err := ParseCronInLocation("30 2 * * *", loc)
+		Expect(err).NotTo(HaveOccurred())
+
+		next := s.Next(time.Date(2024, time.March, 9, 12, 0, 0, 0, loc))
+		Expect(next).To(BeTemporally("==", time.Date(2024, time.March, 11, 2, 30, 0, 0, loc)))
+	})
+
+	It("never matches an impossible date", func() {
+		s, err := ParseCronInLocation("0 0 30 2 *", time.UTC)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Next(at("2000-01-01T00:00:00Z")).IsZero()).To(BeTrue())
+	})
+
+	DescribeTable("rejects invalid expressions",
+		func(expr string) {
+			_, err := ParseCron(expr)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("too few fields", "* * * *"),
+		Entry("out of range", "60 * * * *"),
+		Entry("bad step", "*/0 * * * *"),
+		Entry("reversed range", "0 10-2 * * *"),
+		Entry("unknown name", "0 0 * FOO *"),
+		Entry("unknown time zone", "TZ=Nowhere/Land 0 0 * * *"),
+	)
+})
@@ -0,0 +1,99 @@
+package scheduler
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+)
+
+type Option func(*options)
+
+type options struct {
+	rand     clock.Rand
+	executor clock.Executor
+	onError  func(name string, err error)
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		rand:     defaultRand{},
+		executor: goExecutor{},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+func WithRand(r clock.Rand) Option {
+	return func(o *options) {
+		o.rand = r
+	}
+}
+
+// WithExecutor runs the jobs on e. The default starts a goroutine for each run.
+func WithExecutor(e clock.Executor) Option {
+	return func(o *options) {
+		o.executor = e
+	}
+}
+
+// WithErrorHook is called with the name of the job for every run that fails or panics.
+func WithErrorHook(f func(name string, err error)) Option {
+	return func(o *options) {
+		o.onError = f
+	}
+}
+
+type defaultRand struct{}
+
+func (defaultRand) Float64() float64 {
+	return rand.Float64()
+}
+
+type goExecutor struct{}
+
+func (goExecutor) Go(f func()) {
+	go f()
+}
+
+type JobOption func(*jobOptions)
+
+type jobOptions struct {
+	overlap OverlapPolicy
+	jitter  time.Duration
+	onError func(err error)
+}
+
+func newJobOptions(opts []JobOption) *jobOptions {
+	o := &jobOptions{
+		overlap: OverlapSkip,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithOverlap sets what happens when the job is due while it is still running. The default is OverlapSkip.
+func WithOverlap(policy OverlapPolicy) JobOption {
+	return func(o *jobOptions) {
+		o.overlap = policy
+	}
+}
+
+// WithJitter delays every activation by a random duration in [0, d),
+// so that instances started together do not run their jobs at the same moment.
+func WithJitter(d time.Duration) JobOption {
+	return func(o *jobOptions) {
+		o.jitter = max(0, d)
+	}
+}
+
+// WithJobErrorHook is called for every run of the job that fails or panics, in addition to WithErrorHook.
+func WithJobErrorHook(f func(err error)) JobOption {
+	return func(o *jobOptions) {
+		o.onError = f
+	}
+}
@@ -0,0 +1,253 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	"github.com/pkg/errors"
+)
+
+type Job func(ctx context.Context) error
+
+type OverlapPolicy int
+
+const (
+	// OverlapSkip drops an activation while the previous run is still going.
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue runs the dropped activations one after another once the previous run finishes.
+	OverlapQueue
+	// OverlapAllow starts a new run regardless of the running ones.
+	OverlapAllow
+)
+
+var ErrStopped = errors.New("scheduler is stopped")
+
+// PanicError is reported to the error hooks when a job panics.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("job panicked: %v", e.Value)
+}
+
+type Scheduler struct {
+	clock   clock.Clock
+	options *options
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mutex   sync.Mutex
+	jobs    map[string]*job
+	started bool
+	stopped bool
+
+	timers sync.WaitGroup
+	runs   sync.WaitGroup
+}
+
+type job struct {
+	name     string
+	schedule Schedule
+	fn       Job
+	options  *jobOptions
+	timer    clock.Timer
+	nominal  time.Time // the activation time before jitter, so that jitter does not accumulate
+
+	mutex   sync.Mutex
+	running int
+	queued  int
+}
+
+func New(clock clock.Clock, opts ...Option) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		clock:   clock,
+		options: newOptions(opts),
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		jobs:    make(map[string]*job),
+	}
+}
+
+// Add registers a job. If the scheduler is already started, the job is scheduled right away.
+func (s *Scheduler) Add(name string, schedule Schedule, fn Job, opts ...JobOption) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stopped {
+		return ErrStopped
+	}
+	if _, ok := s.jobs[name]; ok {
+		return errors.Errorf("job %q already exists", name)
+	}
+
+	j := &job{name: name, schedule: schedule, fn: fn, options: newJobOptions(opts)}
+	s.jobs[name] = j
+	if s.started {
+		s.scheduleLocked(j)
+	}
+	return nil
+}
+
+func (s *Scheduler) Start() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.started || s.stopped {
+		return
+	}
+	s.started = true
+	for _, j := range s.jobs {
+		s.scheduleLocked(j)
+	}
+}
+
+// Stop stops scheduling and waits for the running jobs, dropping queued runs.
+// If ctx is done first, the context passed to the jobs is canceled and ctx.Err() is returned.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mutex.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.done)
+		for _, j := range s.jobs {
+			if j.timer != nil && j.timer.Stop() {
+				s.timers.Done()
+			}
+		}
+	}
+	s.mutex.Unlock()
+
+	// A timer that has fired may still have its activation queued on an executor, so it is waited for along with the runs.
+	finished := make(chan struct{})
+	go func() {
+		s.timers.Wait()
+		s.runs.Wait()
+		close(finished)
+	}()
+
+	defer s.cancel()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// scheduleLocked sets a timer for the next activation of j.
+func (s *Scheduler) scheduleLocked(j *job) {
+	j.timer = nil
+
+	// The next activation follows the nominal one that just fired, so that neither jitter nor late timers add up,
+	// unless that activation has already passed, as after the process was suspended.
+	now := s.clock.Now()
+	var next time.Time
+	if !j.nominal.IsZero() {
+		next = j.schedule.Next(j.nominal)
+	}
+	if next.IsZero() || now.Sub(next) > j.options.jitter {
+		next = j.schedule.Next(now)
+	}
+	if next.IsZero() {
+		return
+	}
+	j.nominal = next
+	if j.options.jitter > 0 {
+		next = next.Add(time.Duration(s.options.rand.Float64() * float64(j.options.jitter)))
+	}
+
+	s.timers.Add(1)
+	j.timer = s.clock.AfterFunc(next.Sub(now), func() {
+		defer s.timers.Done()
+		s.activate(j)
+	})
+}
+
+func (s *Scheduler) activate(j *job) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.stopped {
+		return
+	}
+	s.trigger(j)
+	s.scheduleLocked(j)
+}
+
+func (s *Scheduler) trigger(j *job) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if j.running > 0 {
+		switch j.options.overlap {
+		case OverlapSkip:
+			return
+		case OverlapQueue:
+			j.queued++
+			return
+		}
+	}
+
+	j.running++
+	s.runs.Add(1)
+	s.options.executor.Go(func() {
+		s.run(j)
+	})
+}
+
+func (s *Scheduler) run(j *job) {
+	defer s.runs.Done()
+
+	for {
+		s.execute(j)
+
+		j.mutex.Lock()
+		if j.queued == 0 || s.isStopped() {
+			j.queued = 0
+			j.running--
+			j.mutex.Unlock()
+			return
+		}
+		j.queued--
+		j.mutex.Unlock()
+	}
+}
+
+func (s *Scheduler) execute(j *job) {
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r, Stack: debug.Stack()}
+			}
+		}()
+		return j.fn(s.ctx)
+	}()
+	if err == nil {
+		return
+	}
+
+	if j.options.onError != nil {
+		j.options.onError(err)
+	}
+	if s.options.onError != nil {
+		s.options.onError(j.name, err)
+	}
+}
+
+func (s *Scheduler) isStopped() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
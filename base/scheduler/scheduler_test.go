@@ -0,0 +1,277 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	"github.com/omnius-labs/core-go/base/sim"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type randMock struct {
+	value float64
+}
+
+func (r *randMock) Float64() float64 {
+	return r.value
+}
+
+var _ = Describe("Scheduler Test", func() {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	It("runs interval jobs as the clock advances", func() {
+		c := clock.NewFake(start)
+		s := New(c)
+		var runs atomic.Int32
+		Expect(s.Add("a", Every(time.Minute), func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		})).To(Succeed())
+		s.Start()
+
+		for i := 1; i <= 3; i++ {
+			c.BlockUntil(1)
+			c.Advance(time.Minute)
+			Eventually(runs.Load).Should(Equal(int32(i)))
+		}
+		Expect(s.Stop(context.Background())).To(Succeed())
+	})
+
+	It("runs cron jobs", func() {
+		c := clock.NewFake(start.Add(30 * time.Second))
+		s := New(c)
+		ran := make(chan time.Time, 1)
+		cron, err := ParseCronInLocation("*/5 * * * *", time.UTC)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Add("a", cron, func(ctx context.Context) error {
+			ran <- c.Now()
+			return nil
+		})).To(Succeed())
+		s.Start()
+
+		c.BlockUntil(1)
+		c.Advance(4 * time.Minute)
+		Consistently(ran, 50*time.Millisecond).ShouldNot(Receive())
+		c.Advance(30 * time.Second)
+		Eventually(ran).Should(Receive(Equal(start.Add(5 * time.Minute))))
+		Expect(s.Stop(context.Background())).To(Succeed())
+	})
+
+	It("delays activations by jitter", func() {
+		c := clock.NewFake(start)
+		s := New(c, WithRand(&randMock{value: 0.5}))
+		ran := make(chan time.Time, 1)
+		Expect(s.Add("a", Every(time.Minute), func(ctx context.Context) error {
+			ran <- c.Now()
+			return nil
+		}, WithJitter(10*time.Second))).To(Succeed())
+		s.Start()
+
+		c.BlockUntil(1)
+		c.Advance(time.Minute)
+		Consistently(ran, 50*time.Millisecond).ShouldNot(Receive())
+		c.Advance(5 * time.Second)
+		Eventually(ran).Should(Receive(Equal(start.Add(65 * time.Second))))
+		Expect(s.Stop(context.Background())).To(Succeed())
+	})
+
+	It("does not accumulate jitter", func() {
+		sm := sim.New(start)
+		jitter := 10 * time.Second
+		s := New(sm.Clock(), WithExecutor(sm), WithRand(&randMock{value: 0.9}))
+		var ran []time.Time
+		Expect(s.Add("a", Every(time.Minute), func(ctx context.Context) error {
+			ran = append(ran, sm.Clock().Now())
+			return nil
+		}, WithJitter(jitter))).To(Succeed())
+		s.Start()
+
+		sm.Advance(10*time.Minute + 30*time.Second)
+		Expect(ran).To(HaveLen(10))
+		for k, at := range ran {
+			nominal := start.Add(time.Duration(k+1) * time.Minute)
+			Expect(at).To(BeTemporally(">=", nominal))
+			Expect(at).To(BeTemporally("<=", nominal.Add(jitter)))
+		}
+		Expect(s.Stop(context.Background())).To(Succeed())
+	})
+
+	It("keeps to the nominal times when timers fire late", func() {
+		sm := sim.New(start)
+		s := New(sm.Clock(), WithExecutor(sm))
+		var ran []time.Time
+		Expect(s.Add("a", Every(time.Minute), func(ctx context.Context) error {
+			ran = append(ran, sm.Clock().Now())
+			return nil
+		})).To(Succeed())
+		s.Start()
+
+		for k := 1; k <= 5; k++ {
+			next, ok := sm.Clock().Next()
+			Expect(ok).To(BeTrue())
+			Expect(next).To(Equal(start.Add(time.Duration(k) * time.Minute)))
+			sm.Clock().Set(next.Add(5 * time.Millisecond))
+			sm.RunUntilIdle()
+		}
+		Expect(ran).To(HaveLen(5))
+		Expect(ran[4]).To(Equal(start.Add(5*time.Minute + 5*time.Millisecond)))
+		Expect(s.Stop(context.Background())).To(Succeed())
+	})
+
+	DescribeTable("overlap policies",
+		func(policy OverlapPolicy, concurrent int32, total int32) {
+			c := clock.NewFake(start)
+			s := New(c)
+			release := make(chan struct{})
+			var running, maxRunning, runs atomic.Int32
+			Expect(s.Add("a", Every(time.Minute), func(ctx context.Context) error {
+				n := running.Add(1)
+				defer running.Add(-1)
+				for {
+					m := maxRunning.Load()
+					if n <= m || maxRunning.CompareAndSwap(m, n) {
+						break
+					}
+				}
+				runs.Add(1)
+				<-release
+				return nil
+			}, WithOverlap(policy))).To(Succeed())
+			s.Start()
+
+			for i := 0; i < 3; i++ {
+				c.BlockUntil(1)
+				c.Advance(time.Minute)
+			}
+			c.BlockUntil(1)
+			Eventually(running.Load).Should(Equal(concurrent))
+			close(release)
+
+			Eventually(runs.Load).Should(Equal(total))
+			Expect(s.Stop(context.Background())).To(Succeed())
+			Expect(maxRunning.Load()).To(Equal(concurrent))
+			Expect(runs.Load()).To(Equal(total))
+		},
+		Entry("skip", OverlapSkip, int32(1), int32(1)),
+		Entry("queue", OverlapQueue, int32(1), int32(3)),
+		Entry("allow", OverlapAllow, int32(3), int32(3)),
+	)
+
+	It("recovers panics and reports errors", func() {
+		c := clock.NewFake(start)
+		errJob := errors.New("job failed")
+		mutex := sync.Mutex{}
+		var reported []string
+		var jobErrs []error
+		s := New(c, WithErrorHook(func(name string, err error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			reported = append(reported, name)
+		}))
+		Expect(s.Add("panic", Every(time.Minute), func(ctx context.Context) error {
+			panic("boom")
+		}, WithJobErrorHook(func(err error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			jobErrs = append(jobErrs, err)
+		}))).To(Succeed())
+		Expect(s.Add("fail", Every(time.Minute), func(ctx context.Context) error {
+			return errJob
+		})).To(Succeed())
+		s.Start()
+
+		c.BlockUntil(2)
+		c.Advance(time.Minute)
+		Eventually(func() int {
+			mutex.Lock()
+			defer mutex.Unlock()
+			return len(reported)
+		}).Should(Equal(2))
+		Expect(s.Stop(context.Background())).To(Succeed())
+
+		Expect(reported).To(ConsistOf("panic", "fail"))
+		Expect(jobErrs).To(HaveLen(1))
+		var panicErr *PanicError
+		Expect(errors.As(jobErrs[0], &panicErr)).To(BeTrue())
+		Expect(panicErr.Value).To(Equal("boom"))
+		Expect(panicErr.Stack).NotTo(BeEmpty())
+	})
+
+	It("cancels running jobs when Stop times out", func() {
+		c := clock.NewFake(start)
+		s := New(c)
+		started := make(chan struct{})
+		canceled := make(chan struct{})
+		Expect(s.Add("a", Every(time.Minute), func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			close(canceled)
+			return ctx.Err()
+		})).To(Succeed())
+		s.Start()
+
+		c.BlockUntil(1)
+		c.Advance(time.Minute)
+		Eventually(started).Should(BeClosed())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Expect(s.Stop(ctx)).To(MatchError(context.Canceled))
+		Eventually(canceled).Should(BeClosed())
+
+		Expect(s.Add("b", Every(time.Minute), func(ctx context.Context) error { return nil })).To(MatchError(ErrStopped))
+	})
+
+	It("runs deterministically on a simulation", func() {
+		sm := sim.New(start)
+		s := New(sm.Clock(), WithExecutor(sm))
+		var ran []time.Time
+		Expect(s.Add("a", Every(time.Minute), func(ctx context.Context) error {
+			ran = append(ran, sm.Clock().Now())
+			return nil
+		})).To(Succeed())
+		s.Start()
+
+		sm.Advance(3*time.Minute + 30*time.Second)
+		Expect(ran).To(Equal([]time.Time{start.Add(time.Minute), start.Add(2 * time.Minute), start.Add(3 * time.Minute)}))
+
+		Expect(s.Stop(context.Background())).To(Succeed())
+		sm.Advance(time.Hour)
+		Expect(ran).To(HaveLen(3))
+		Expect(sm.CheckLeaks()).To(Succeed())
+	})
+
+	It("stops at the deadline while an activation is queued", func() {
+		sm := sim.New(start)
+		s := New(sm.Clock(), WithExecutor(sm))
+		var runs int
+		Expect(s.Add("a", Every(time.Minute), func(ctx context.Context) error {
+			runs++
+			return nil
+		})).To(Succeed())
+		s.Start()
+
+		sm.Clock().Set(start.Add(time.Minute))
+		Expect(sm.Pending()).To(Equal(1))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		Expect(s.Stop(ctx)).To(MatchError(context.Canceled))
+
+		sm.RunUntilIdle()
+		Expect(runs).To(Equal(0))
+		Expect(sm.CheckLeaks()).To(Succeed())
+	})
+
+	It("rejects duplicate names", func() {
+		s := New(clock.NewFake(start))
+		job := func(ctx context.Context) error { return nil }
+		Expect(s.Add("a", Every(time.Minute), job)).To(Succeed())
+		Expect(s.Add("a", Every(time.Minute), job)).To(HaveOccurred())
+	})
+})
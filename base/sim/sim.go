@@ -0,0 +1,115 @@
+package sim
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/omnius-labs/core-go/base/clock"
+	"github.com/pkg/errors"
+)
+
+// Sim pairs a fake clock with an executor that only runs tasks when told to, so that
+// background work happens at well defined points of a test.
+//
+// Tasks run one at a time on the goroutine that calls Step, RunUntilIdle or Advance.
+// A task must not block on something that only another task can do, such as sleeping on the clock.
+type Sim struct {
+	clock *clock.FakeClock
+
+	mutex sync.Mutex
+	queue []*task
+}
+
+type task struct {
+	f     func()
+	stack []byte
+}
+
+// New returns a simulation starting at now. The AfterFunc callbacks of its clock are run as tasks.
+func New(now time.Time) *Sim {
+	s := &Sim{}
+	s.clock = clock.NewFake(now, clock.WithExecutor(s))
+	return s
+}
+
+func (s *Sim) Clock() *clock.FakeClock {
+	return s.clock
+}
+
+// Go queues f to run on the next Step.
+func (s *Sim) Go(f func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.queue = append(s.queue, &task{f: f, stack: debug.Stack()})
+}
+
+// Pending returns the number of queued tasks.
+func (s *Sim) Pending() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return len(s.queue)
+}
+
+// Step runs the oldest queued task, and reports whether there was one.
+func (s *Sim) Step() bool {
+	s.mutex.Lock()
+	if len(s.queue) == 0 {
+		s.mutex.Unlock()
+		return false
+	}
+	t := s.queue[0]
+	s.queue[0] = nil
+	s.queue = s.queue[1:]
+	s.mutex.Unlock()
+
+	t.f()
+	return true
+}
+
+// RunUntilIdle runs tasks, including the ones they queue, until none are left, and returns how many ran.
+func (s *Sim) RunUntilIdle() int {
+	n := 0
+	for s.Step() {
+		n++
+	}
+	return n
+}
+
+// Advance moves the clock forward by d, stopping at each timer deadline on the way to run the tasks until idle,
+// so that work scheduled by a task sees the clock at the time it was triggered.
+func (s *Sim) Advance(d time.Duration) {
+	end := s.clock.Now().Add(d)
+
+	s.RunUntilIdle()
+	for {
+		next, ok := s.clock.Next()
+		if !ok || next.After(end) {
+			break
+		}
+		s.clock.Set(next)
+		s.RunUntilIdle()
+	}
+	s.clock.Set(end)
+	s.RunUntilIdle()
+}
+
+// CheckLeaks returns an error listing where the tasks that are still queued were started.
+func (s *Sim) CheckLeaks() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.queue) == 0 {
+		return nil
+	}
+
+	b := strings.Builder{}
+	for i, t := range s.queue {
+		fmt.Fprintf(&b, "\ntask %d started at:\n%s", i+1, t.stack)
+	}
+	return errors.Errorf("%d tasks leaked:%s", len(s.queue), b.String())
+}
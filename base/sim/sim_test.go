@@ -0,0 +1,71 @@
+package sim
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sim Test", func() {
+	start := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	It("runs tasks only when stepped", func() {
+		s := New(start)
+		var ran []int
+		s.Go(func() { ran = append(ran, 1) })
+		s.Go(func() {
+			ran = append(ran, 2)
+			s.Go(func() { ran = append(ran, 3) })
+		})
+		Expect(ran).To(BeEmpty())
+		Expect(s.Pending()).To(Equal(2))
+
+		Expect(s.Step()).To(BeTrue())
+		Expect(ran).To(Equal([]int{1}))
+
+		Expect(s.RunUntilIdle()).To(Equal(2))
+		Expect(ran).To(Equal([]int{1, 2, 3}))
+		Expect(s.Step()).To(BeFalse())
+		Expect(s.CheckLeaks()).To(Succeed())
+	})
+
+	It("runs timer callbacks in deadline order at their own time", func() {
+		s := New(start)
+		c := s.Clock()
+		var at []time.Duration
+		ticks := 0
+		var tick func()
+		tick = func() {
+			at = append(at, c.Since(start))
+			if ticks++; ticks < 3 {
+				c.AfterFunc(time.Minute, tick)
+			}
+		}
+		c.AfterFunc(time.Minute, tick)
+		c.AfterFunc(90*time.Second, func() {
+			at = append(at, -c.Since(start))
+		})
+
+		s.Advance(10 * time.Minute)
+		Expect(at).To(Equal([]time.Duration{time.Minute, -90 * time.Second, 2 * time.Minute, 3 * time.Minute}))
+		Expect(c.Now()).To(Equal(start.Add(10 * time.Minute)))
+	})
+
+	It("queues timer callbacks fired by the clock directly", func() {
+		s := New(start)
+		ran := false
+		s.Clock().AfterFunc(time.Second, func() { ran = true })
+
+		s.Clock().Advance(time.Second)
+		Expect(ran).To(BeFalse())
+		Expect(s.Pending()).To(Equal(1))
+
+		err := s.CheckLeaks()
+		Expect(err).To(MatchError(ContainSubstring("1 tasks leaked")))
+		Expect(err.Error()).To(ContainSubstring("sim_test.go"))
+
+		s.RunUntilIdle()
+		Expect(ran).To(BeTrue())
+	})
+})
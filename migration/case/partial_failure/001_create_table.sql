@@ -0,0 +1,4 @@
+create table example(
+    id text not null,
+    name text not null
+);
@@ -0,0 +1,7 @@
+create table broken(
+    id text not null
+);
+insert into broken (id) values ('a');
+create table example2(
+    id text not null,,,,, -- ERROR
+);
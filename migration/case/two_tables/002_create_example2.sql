@@ -0,0 +1,3 @@
+create table example2(
+    id text not null
+);
@@ -0,0 +1,3 @@
+create table users(
+    id text not null
+);
@@ -0,0 +1,3 @@
+create table posts(
+    id text not null
+);
@@ -0,0 +1 @@
+create index users_id on users(id);
@@ -0,0 +1,67 @@
+package migration
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// Driver opens a database for the URL scheme it is registered under.
+// username and description identify the holder of the migration lock, for dialects that record it.
+type Driver interface {
+	Open(url string, username string, description string) (*sqlx.DB, Dialect, error)
+}
+
+var (
+	driversMutex sync.RWMutex
+	drivers      = make(map[string]Driver)
+)
+
+// Register makes a driver available to Open by URL scheme, such as "mysql" for mysql://.
+// It panics if the scheme is already registered.
+func Register(scheme string, driver Driver) {
+	driversMutex.Lock()
+	defer driversMutex.Unlock()
+
+	if _, ok := drivers[scheme]; ok {
+		panic("migration: Register called twice for scheme " + scheme)
+	}
+	drivers[scheme] = driver
+}
+
+// Drivers returns the registered schemes, sorted.
+func Drivers() []string {
+	driversMutex.RLock()
+	defer driversMutex.RUnlock()
+
+	var schemes []string
+	for scheme := range drivers {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Open returns a migrator for the files in path, using the driver registered for the scheme of url.
+func Open(url string, path string, username string, description string) (Migrator, error) {
+	scheme, _, ok := strings.Cut(url, "://")
+	if !ok {
+		return nil, errors.Errorf("missing scheme in url")
+	}
+
+	driversMutex.RLock()
+	driver, ok := drivers[scheme]
+	driversMutex.RUnlock()
+	if !ok {
+		return nil, errors.Errorf("unknown scheme %q", scheme)
+	}
+
+	db, dialect, err := driver.Open(url, username, description)
+	if err != nil {
+		return nil, err
+	}
+	return NewEngine(db, dialect, path), nil
+}
@@ -0,0 +1,98 @@
+package migration
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	upSuffix   = ".up.sql"
+	downSuffix = ".down.sql"
+)
+
+// MigrationFile is a forward file, or an NNN_name.up.sql file together with its NNN_name.down.sql.
+// Down is nil when there is no down file, which makes the migration irreversible.
+type MigrationFile struct {
+	Filename string  `db:"filename"`
+	Queries  string  `db:"queries"`
+	Down     *string `db:"down_queries"`
+}
+
+// Version is the number the filename starts with, or 0 if it does not start with one.
+func (f *MigrationFile) Version() int64 {
+	return parseVersion(f.Filename)
+}
+
+type MigrationHistory struct {
+	Filename    string     `db:"filename"`
+	ExecutedAt  *time.Time `db:"executed_at"`
+	DownQueries *string    `db:"down_queries"`
+}
+
+func (h *MigrationHistory) Version() int64 {
+	return parseVersion(h.Filename)
+}
+
+func parseVersion(filename string) int64 {
+	end := strings.IndexFunc(filename, func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+	if end < 0 {
+		end = len(filename)
+	}
+	v, err := strconv.ParseInt(filename[:end], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// loadMigrationFiles reads the files in path, sorted by name, pairing down files with their up files.
+func loadMigrationFiles(path string) ([]*MigrationFile, error) {
+	var results []*MigrationFile
+	downs := make(map[string]string)
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			name := entry.Name()
+			content, err := os.ReadFile(filepath.Join(path, name))
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			queries := string(content)
+			if base, ok := strings.CutSuffix(name, downSuffix); ok {
+				downs[base+upSuffix] = queries
+				continue
+			}
+			result := &MigrationFile{Filename: name, Queries: queries}
+			results = append(results, result)
+		}
+	}
+
+	for _, result := range results {
+		if down, ok := downs[result.Filename]; ok {
+			result.Down = &down
+			delete(downs, result.Filename)
+		}
+	}
+	for name := range downs {
+		return nil, errors.Errorf("%s has no up file", strings.TrimSuffix(name, upSuffix)+downSuffix)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Filename < results[j].Filename
+	})
+
+	return results, nil
+}
@@ -0,0 +1,409 @@
+package migration
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+var ErrIrreversible = errors.New("migration is irreversible")
+
+type Migrator interface {
+	// Migrate applies the files that are not in the history yet.
+	Migrate() error
+	// Rollback undoes the last steps applied migrations, the latest first.
+	Rollback(steps int) error
+	// MigrateTo applies the pending files up to version, and undoes the applied ones after it.
+	MigrateTo(version int64) error
+	// Status lists every file and every applied migration, sorted by name.
+	Status() ([]*MigrationStatus, error)
+	// Plan returns the files Migrate would apply, in order.
+	Plan() ([]*MigrationFile, error)
+}
+
+type MigrationStatus struct {
+	Filename   string
+	Applied    bool
+	ExecutedAt *time.Time
+	// Missing is true for an applied migration whose file is gone.
+	Missing bool
+	// Reversible is true if the migration has down queries, recorded at apply time once it is applied.
+	Reversible bool
+}
+
+// Dialect is what differs between databases: locking, the history table and how statements are run.
+type Dialect interface {
+	// Lock keeps other migrators out until unlock is called. It is taken on the connection the migrations run on.
+	Lock(ctx context.Context, conn *sqlx.Conn) (unlock func() error, err error)
+	// CreateHistoryTable returns the DDL that creates _migrations if it does not exist.
+	CreateHistoryTable() string
+	// HistoryTableExists returns a query that selects 1 if _migrations exists and 0 otherwise.
+	HistoryTableExists() string
+	// Begin starts the transaction a file is applied in.
+	Begin(ctx context.Context, conn *sqlx.Conn) (Tx, error)
+	// Exec runs the queries of a file.
+	Exec(ctx context.Context, e sqlx.ExecerContext, queries string) error
+}
+
+type Tx interface {
+	sqlx.ExecerContext
+	sqlx.QueryerContext
+	Rebind(query string) string
+	Commit() error
+	Rollback() error
+}
+
+var _ Migrator = (*Engine)(nil)
+
+// Engine loads migration files and plans and applies them through a Dialect.
+type Engine struct {
+	db      *sqlx.DB
+	dialect Dialect
+	path    string
+}
+
+func NewEngine(db *sqlx.DB, dialect Dialect, path string) *Engine {
+	return &Engine{db, dialect, path}
+}
+
+// Migrate returns without locking when nothing is pending, and plans again once it holds the lock.
+func (m *Engine) Migrate() error {
+	files, err := m.Plan()
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	return m.run(m.migrate)
+}
+
+func (m *Engine) Rollback(steps int) error {
+	return m.run(func(ctx context.Context, conn *sqlx.Conn) error {
+		histories, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		return m.revertAll(ctx, conn, histories[:min(max(steps, 0), len(histories))])
+	})
+}
+
+func (m *Engine) MigrateTo(version int64) error {
+	return m.run(func(ctx context.Context, conn *sqlx.Conn) error {
+		histories, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		var reverts []*MigrationHistory
+		for _, h := range histories {
+			if h.Version() > version {
+				reverts = append(reverts, h)
+			}
+		}
+		err = m.revertAll(ctx, conn, reverts)
+		if err != nil {
+			return err
+		}
+
+		files, err := m.plan(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			if f.Version() > version {
+				continue
+			}
+			err = m.apply(ctx, conn, f)
+			if err != nil {
+				return errors.Wrapf(err, "failed to migrate %s", f.Filename)
+			}
+		}
+		return nil
+	})
+}
+
+// run creates the history table and calls f, holding the lock of the dialect.
+// Session-level locks belong to a connection, so everything runs on one.
+func (m *Engine) run(f func(ctx context.Context, conn *sqlx.Conn) error) error {
+	ctx := context.Background()
+
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to get connection")
+	}
+	defer conn.Close()
+
+	unlock, err := m.dialect.Lock(ctx, conn)
+	if err != nil {
+		return errors.Wrap(err, "failed to lock")
+	}
+
+	result := m.init(ctx, conn)
+	if result != nil {
+		result = errors.Wrap(result, "failed to init")
+	} else {
+		result = f(ctx, conn)
+	}
+
+	err = unlock()
+	if err != nil && result == nil {
+		return errors.Wrap(err, "failed to unlock")
+	}
+
+	return result
+}
+
+func (m *Engine) migrate(ctx context.Context, conn *sqlx.Conn) error {
+	files, err := m.plan(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range files {
+		err = m.apply(ctx, conn, f)
+		if err != nil {
+			return errors.Wrapf(err, "failed to migrate %s", f.Filename)
+		}
+	}
+
+	return nil
+}
+
+func (m *Engine) Status() ([]*MigrationStatus, error) {
+	ctx := context.Background()
+
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get connection")
+	}
+	defer conn.Close()
+
+	histories, err := m.histories(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	files, err := loadMigrationFiles(m.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to loadMigrationFiles")
+	}
+
+	statuses := make(map[string]*MigrationStatus)
+	for _, f := range files {
+		statuses[f.Filename] = &MigrationStatus{Filename: f.Filename, Reversible: f.Down != nil}
+	}
+	for _, h := range histories {
+		s, ok := statuses[h.Filename]
+		if !ok {
+			s = &MigrationStatus{Filename: h.Filename, Missing: true}
+			statuses[h.Filename] = s
+		}
+		s.Applied = true
+		s.ExecutedAt = h.ExecutedAt
+		s.Reversible = h.DownQueries != nil
+	}
+
+	results := make([]*MigrationStatus, 0, len(statuses))
+	for _, s := range statuses {
+		results = append(results, s)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Filename < results[j].Filename
+	})
+	return results, nil
+}
+
+func (m *Engine) Plan() ([]*MigrationFile, error) {
+	ctx := context.Background()
+
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get connection")
+	}
+	defer conn.Close()
+
+	return m.plan(ctx, conn)
+}
+
+func (m *Engine) plan(ctx context.Context, conn *sqlx.Conn) ([]*MigrationFile, error) {
+	histories, err := m.histories(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	ignoreSet := make(map[string]bool)
+	for _, history := range histories {
+		ignoreSet[history.Filename] = true
+	}
+
+	files, err := loadMigrationFiles(m.path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to loadMigrationFiles")
+	}
+
+	var filteredFiles []*MigrationFile
+	for _, file := range files {
+		if !ignoreSet[file.Filename] {
+			filteredFiles = append(filteredFiles, file)
+		}
+	}
+	return filteredFiles, nil
+}
+
+// histories reads the history without changing it, so a missing table or down_queries column reads as empty.
+func (m *Engine) histories(ctx context.Context, conn *sqlx.Conn) ([]*MigrationHistory, error) {
+	columns, err := m.historyColumns(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	if columns == nil {
+		return nil, nil
+	}
+
+	query := "SELECT filename, executed_at FROM _migrations"
+	if columns["down_queries"] {
+		query = "SELECT filename, executed_at, down_queries FROM _migrations"
+	}
+
+	var histories []*MigrationHistory
+	err = conn.SelectContext(ctx, &histories, query)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetchMigrationHistories")
+	}
+	return histories, nil
+}
+
+// historyColumns returns the columns of the history table, or nil if it does not exist.
+func (m *Engine) historyColumns(ctx context.Context, conn *sqlx.Conn) (map[string]bool, error) {
+	var exists int
+	err := conn.GetContext(ctx, &exists, m.dialect.HistoryTableExists())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetchMigrationHistories")
+	}
+	if exists == 0 {
+		return nil, nil
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT * FROM _migrations WHERE 1 = 0")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetchMigrationHistories")
+	}
+	defer rows.Close()
+
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetchMigrationHistories")
+	}
+	columns := make(map[string]bool)
+	for _, name := range names {
+		columns[strings.ToLower(name)] = true
+	}
+	return columns, nil
+}
+
+// applied returns the histories from the latest migration to the first.
+func (m *Engine) applied(ctx context.Context, conn *sqlx.Conn) ([]*MigrationHistory, error) {
+	histories, err := m.histories(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(histories, func(i, j int) bool {
+		return histories[i].Filename > histories[j].Filename
+	})
+	return histories, nil
+}
+
+// init creates the history table, and adds the down_queries column to a table created before it existed.
+func (m *Engine) init(ctx context.Context, conn *sqlx.Conn) error {
+	err := m.dialect.Exec(ctx, conn, m.dialect.CreateHistoryTable())
+	if err != nil {
+		return err
+	}
+
+	columns, err := m.historyColumns(ctx, conn)
+	if err != nil || columns["down_queries"] {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, "ALTER TABLE _migrations ADD COLUMN down_queries TEXT")
+	return errors.WithStack(err)
+}
+
+// apply runs a file and records it in one transaction, unless another migrator has applied it in the meantime.
+func (m *Engine) apply(ctx context.Context, conn *sqlx.Conn, f *MigrationFile) error {
+	tx, err := m.dialect.Begin(ctx, conn)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = func() error {
+		var count int
+		err := sqlx.GetContext(ctx, tx, &count, tx.Rebind("SELECT COUNT(*) FROM _migrations WHERE filename = ?"), f.Filename)
+		if err != nil || count > 0 {
+			return errors.WithStack(err)
+		}
+
+		err = m.dialect.Exec(ctx, tx, f.Queries)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		_, err = tx.ExecContext(ctx, tx.Rebind("INSERT INTO _migrations (filename, queries, down_queries) VALUES (?, ?, ?)"), f.Filename, f.Queries, f.Down)
+		return errors.WithStack(err)
+	}()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return errors.WithStack(tx.Commit())
+}
+
+// revertAll undoes histories in order. Nothing is undone if one of them is irreversible.
+func (m *Engine) revertAll(ctx context.Context, conn *sqlx.Conn, histories []*MigrationHistory) error {
+	for _, h := range histories {
+		if h.DownQueries == nil {
+			return errors.Wrapf(ErrIrreversible, "failed to rollback %s", h.Filename)
+		}
+	}
+
+	for _, h := range histories {
+		err := m.revert(ctx, conn, h)
+		if err != nil {
+			return errors.Wrapf(err, "failed to rollback %s", h.Filename)
+		}
+	}
+	return nil
+}
+
+// revert runs the down queries recorded when h was applied, and removes it from the history in one transaction.
+func (m *Engine) revert(ctx context.Context, conn *sqlx.Conn, h *MigrationHistory) error {
+	tx, err := m.dialect.Begin(ctx, conn)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	err = func() error {
+		var count int
+		err := sqlx.GetContext(ctx, tx, &count, tx.Rebind("SELECT COUNT(*) FROM _migrations WHERE filename = ?"), h.Filename)
+		if err != nil || count == 0 {
+			return errors.WithStack(err)
+		}
+
+		err = m.dialect.Exec(ctx, tx, *h.DownQueries)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+
+		_, err = tx.ExecContext(ctx, tx.Rebind("DELETE FROM _migrations WHERE filename = ?"), h.Filename)
+		return errors.WithStack(err)
+	}()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return errors.WithStack(tx.Commit())
+}
@@ -0,0 +1,120 @@
+package migration
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/jmoiron/sqlx"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Migrator Test", func() {
+	var url string
+
+	BeforeEach(func() {
+		url = "sqlite://" + filepath.Join(GinkgoT().TempDir(), "test.db")
+	})
+
+	filenames := func(files []*MigrationFile) []string {
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Filename)
+		}
+		return names
+	}
+
+	It("registers drivers by scheme", func() {
+		Expect(Drivers()).To(Equal([]string{"mysql", "postgres", "postgresql", "sqlite"}))
+	})
+
+	It("plans and reports status", func() {
+		m, err := Open(url, "./case/simple_create_table", "", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		plan, err := m.Plan()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filenames(plan)).To(Equal([]string{"init.sql"}))
+
+		Expect(m.Migrate()).To(Succeed())
+
+		plan, err = m.Plan()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan).To(BeEmpty())
+
+		m, err = Open(url, "./case/two_tables", "", "")
+		Expect(err).NotTo(HaveOccurred())
+
+		plan, err = m.Plan()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filenames(plan)).To(Equal([]string{"001_create_example.sql", "002_create_example2.sql"}))
+
+		statuses, err := m.Status()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statuses).To(HaveLen(3))
+		Expect(*statuses[0]).To(Equal(MigrationStatus{Filename: "001_create_example.sql"}))
+		Expect(*statuses[1]).To(Equal(MigrationStatus{Filename: "002_create_example2.sql"}))
+		Expect(statuses[2].Filename).To(Equal("init.sql"))
+		Expect(statuses[2].Applied).To(BeTrue())
+		Expect(statuses[2].Missing).To(BeTrue())
+		Expect(statuses[2].ExecutedAt).NotTo(BeNil())
+	})
+
+	It("plans without changing the database", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "test.db")
+		db, err := sqlx.Open("sqlite", path)
+		Expect(err).NotTo(HaveOccurred())
+		defer db.Close()
+		tables := func() []string {
+			var names []string
+			Expect(db.Select(&names, "SELECT name FROM sqlite_master WHERE type = 'table'")).To(Succeed())
+			return names
+		}
+
+		m, err := NewSQLiteMigrator(path, "./case/simple_create_table")
+		Expect(err).NotTo(HaveOccurred())
+		plan, err := m.Plan()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(filenames(plan)).To(Equal([]string{"init.sql"}))
+		statuses, err := m.Status()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statuses).To(HaveLen(1))
+		Expect(tables()).To(BeEmpty())
+
+		_, err = db.Exec("CREATE TABLE _migrations (filename VARCHAR(255) NOT NULL, queries TEXT NOT NULL, executed_at DATETIME DEFAULT CURRENT_TIMESTAMP)")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = db.Exec("INSERT INTO _migrations (filename, queries) VALUES ('init.sql', '')")
+		Expect(err).NotTo(HaveOccurred())
+
+		statuses, err = m.Status()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statuses[0].Applied).To(BeTrue())
+		Expect(statuses[0].Reversible).To(BeFalse())
+		Expect(m.Migrate()).To(Succeed())
+
+		var columns []string
+		Expect(db.Select(&columns, "SELECT name FROM pragma_table_info('_migrations')")).To(Succeed())
+		Expect(columns).NotTo(ContainElement("down_queries"))
+	})
+
+	It("reports errors reading the history", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "test.db")
+		Expect(os.WriteFile(path, bytes.Repeat([]byte("not a database"), 100), 0o644)).To(Succeed())
+		m, err := NewSQLiteMigrator(path, "./case/simple_create_table")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = m.Plan()
+		Expect(err).To(HaveOccurred())
+		_, err = m.Status()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects unknown schemes", func() {
+		_, err := Open("oracle://localhost", "./case/simple_create_table", "", "")
+		Expect(err).To(MatchError(ContainSubstring("unknown scheme")))
+
+		_, err = Open("test.db", "./case/simple_create_table", "", "")
+		Expect(err).To(MatchError(ContainSubstring("missing scheme")))
+	})
+})
@@ -1,203 +1,100 @@
 package migration
 
 import (
-	"os"
-	"path/filepath"
-	"sort"
+	"context"
 	"strings"
-	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 	"github.com/pkg/errors"
 )
 
+func init() {
+	Register("mysql", mysqlDriver{})
+}
+
 type MySQLMigrator struct {
-	db          *sqlx.DB
-	path        string
-	username    string
-	description string
+	*Engine
 }
 
 func NewMySQLMigrator(url string, path string, username string, description string) (*MySQLMigrator, error) {
-	db, err := sqlx.Open("mysql", url)
+	db, dialect, err := mysqlDriver{}.Open(url, username, description)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to database connect")
+		return nil, err
 	}
-	return &MySQLMigrator{db, path, username, description}, nil
+	return &MySQLMigrator{NewEngine(db, dialect, path)}, nil
 }
 
-func (m *MySQLMigrator) Migrate() error {
-	err := m.init()
-	if err != nil {
-		return errors.Wrap(err, "failed to init")
-	}
-
-	histories, err := m.fetchMigrationHistories()
-	if err != nil {
-		return errors.Wrap(err, "failed to fetchMigrationHistories")
-	}
-
-	ignoreSet := make(map[string]bool)
-	for _, history := range histories {
-		ignoreSet[history.Filename] = true
-	}
-
-	files, err := m.loadMigrationFiles()
-	if err != nil {
-		return errors.Wrap(err, "failed to loadMigrationFiles")
-	}
-
-	var filteredFiles []*MySQLMigrationFile
-	for _, file := range files {
-		if !ignoreSet[file.Filename] {
-			filteredFiles = append(filteredFiles, file)
-		}
-	}
-
-	if len(filteredFiles) == 0 {
-		return nil
-	}
+type mysqlDriver struct{}
 
-	err = m.semaphoreLock()
+// Open takes a go-sql-driver DSN, optionally prefixed with mysql://.
+func (mysqlDriver) Open(url string, username string, description string) (*sqlx.DB, Dialect, error) {
+	db, err := sqlx.Open("mysql", strings.TrimPrefix(url, "mysql://"))
 	if err != nil {
-		return errors.Wrap(err, "failed to semaphoreLock")
-	}
-
-	result := m.executeMigrationQueries(filteredFiles)
-
-	err = m.semaphoreUnlock()
-	if err != nil {
-		return errors.Wrap(err, "failed to semaphoreUnlock")
+		return nil, nil, errors.Wrap(err, "failed to database connect")
 	}
+	return db, &mysqlDialect{username, description}, nil
+}
 
-	return result
+// mysqlDialect locks by inserting a row into _semaphores, so a migrator that died holding the lock
+// keeps the others out until the row is deleted by hand.
+type mysqlDialect struct {
+	username    string
+	description string
 }
 
-func (m *MySQLMigrator) init() error {
-	queries := `
-CREATE TABLE IF NOT EXISTS _migrations (
-    filename VARCHAR(255) NOT NULL,
-    queries TEXT NOT NULL,
-    executed_at DATETIME default CURRENT_TIMESTAMP,
-    PRIMARY KEY (filename)
-);
+func (d *mysqlDialect) Lock(ctx context.Context, conn *sqlx.Conn) (func() error, error) {
+	query := `
 CREATE TABLE IF NOT EXISTS _semaphores (
     username VARCHAR(255) NOT NULL,
     description TEXT NOT NULL,
     executed_at DATETIME default CURRENT_TIMESTAMP,
     PRIMARY KEY (username)
-);
-`
-	return executeMultipleQueries(m.db, queries)
-}
-
-func (m *MySQLMigrator) fetchMigrationHistories() ([]*MySQLMigrationHistory, error) {
-	var histories []*MySQLMigrationHistory
-	err := m.db.Select(&histories, "SELECT filename, executed_at FROM _migrations")
+)`
+	_, err := conn.ExecContext(ctx, query)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
-	return histories, nil
-}
-
-func (m *MySQLMigrator) loadMigrationFiles() ([]*MySQLMigrationFile, error) {
-	var results []*MySQLMigrationFile
 
-	entries, err := os.ReadDir(m.path)
+	_, err = conn.ExecContext(ctx, "INSERT INTO _semaphores (username, description) VALUES (?, ?)", d.username, d.description)
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			name := entry.Name()
-			content, err := os.ReadFile(filepath.Join(m.path, name))
-			if err != nil {
-				return nil, errors.WithStack(err)
-			}
-			queries := string(content)
-			result := &MySQLMigrationFile{Filename: name, Queries: queries}
-			results = append(results, result)
-		}
-	}
-
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Filename < results[j].Filename
-	})
-
-	return results, nil
+	return func() error {
+		_, err := conn.ExecContext(ctx, "DELETE FROM _semaphores WHERE username = ?", d.username)
+		return errors.WithStack(err)
+	}, nil
 }
 
-func (m *MySQLMigrator) executeMigrationQueries(files []*MySQLMigrationFile) error {
-	for _, f := range files {
-		tx, err := m.db.Beginx()
-		if err != nil {
-			return errors.WithStack(err)
-		}
-
-		err = executeMultipleQueriesTx(tx, f.Queries)
-		if err != nil {
-			tx.Rollback()
-			return errors.WithStack(err)
-		}
-
-		err = m.insertMigrationHistory(tx, f.Filename, f.Queries)
-		if err != nil {
-			tx.Rollback()
-			return errors.WithStack(err)
-		}
-
-		err = tx.Commit()
-		if err != nil {
-			return errors.WithStack(err)
-		}
-	}
-
-	return nil
-}
-
-func (m *MySQLMigrator) insertMigrationHistory(tx *sqlx.Tx, filename string, queries string) error {
-	statement := "INSERT INTO _migrations (filename, queries) VALUES (?, ?)"
-	_, err := tx.Exec(statement, filename, queries)
-	return errors.WithStack(err)
-}
-
-func (m *MySQLMigrator) semaphoreLock() error {
-	query := "INSERT INTO _semaphores (username, description) VALUES (?, ?)"
-	_, err := m.db.Exec(query, m.username, m.description)
-	return errors.WithStack(err)
+func (d *mysqlDialect) CreateHistoryTable() string {
+	return `
+CREATE TABLE IF NOT EXISTS _migrations (
+    filename VARCHAR(255) NOT NULL,
+    queries TEXT NOT NULL,
+    down_queries TEXT,
+    executed_at DATETIME default CURRENT_TIMESTAMP,
+    PRIMARY KEY (filename)
+)`
 }
 
-func (m *MySQLMigrator) semaphoreUnlock() error {
-	query := "DELETE FROM _semaphores WHERE username = ?"
-	_, err := m.db.Exec(query, m.username)
-	return errors.WithStack(err)
+func (d *mysqlDialect) HistoryTableExists() string {
+	return "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = '_migrations'"
 }
 
-func executeMultipleQueries(db *sqlx.DB, queries string) error {
-	splitQueries := strings.Split(queries, ";")
-
-	for _, query := range splitQueries {
-		query = strings.TrimSpace(query)
-		if query != "" {
-			_, err := db.Exec(query)
-			if err != nil {
-				return errors.Wrapf(err, "failed to execute query: %s", query)
-			}
-		}
-	}
-
-	return nil
+// Begin starts a transaction, though MySQL commits DDL implicitly, so a failing file may leave part of it applied.
+func (d *mysqlDialect) Begin(ctx context.Context, conn *sqlx.Conn) (Tx, error) {
+	return conn.BeginTxx(ctx, nil)
 }
 
-func executeMultipleQueriesTx(tx *sqlx.Tx, queries string) error {
+// Exec runs the statements one by one, since the driver does not allow several in one query by default.
+func (d *mysqlDialect) Exec(ctx context.Context, e sqlx.ExecerContext, queries string) error {
 	splitQueries := strings.Split(queries, ";")
 
 	for _, query := range splitQueries {
 		query = strings.TrimSpace(query)
 		if query != "" {
-			_, err := tx.Exec(query)
+			_, err := e.ExecContext(ctx, query)
 			if err != nil {
 				return errors.Wrapf(err, "failed to execute query: %s", query)
 			}
@@ -207,12 +104,6 @@ func executeMultipleQueriesTx(tx *sqlx.Tx, queries string) error {
 	return nil
 }
 
-type MySQLMigrationFile struct {
-	Filename string `db:"filename"`
-	Queries  string `db:"queries"`
-}
+type MySQLMigrationFile = MigrationFile
 
-type MySQLMigrationHistory struct {
-	Filename   string     `db:"filename"`
-	ExecutedAt *time.Time `db:"executed_at"`
-}
+type MySQLMigrationHistory = MigrationHistory
@@ -0,0 +1,102 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var _ = Describe("Postgres Migration Test", func() {
+	var postgresC testcontainers.Container
+	var url string
+
+	BeforeEach(func() {
+		ctx := context.Background()
+		req := testcontainers.ContainerRequest{
+			Image:        "postgres:16",
+			ExposedPorts: []string{"5432/tcp"},
+			Env: map[string]string{
+				"POSTGRES_PASSWORD": "password",
+			},
+			WaitingFor: wait.ForAll(wait.ForListeningPort("5432/tcp"), wait.ForLog("database system is ready to accept connections").WithOccurrence(2)),
+		}
+		var err error
+		postgresC, err = testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+			ContainerRequest: req,
+			Started:          true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		ip, err := postgresC.Host(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		port, err := postgresC.MappedPort(ctx, "5432")
+		Expect(err).NotTo(HaveOccurred())
+
+		url = fmt.Sprintf("postgres://postgres:password@%s:%s/postgres?sslmode=disable", ip, port.Port())
+	})
+
+	AfterEach(func() {
+		ctx := context.Background()
+		postgresC.Terminate(ctx)
+	})
+
+	It("simple create table test", Serial, func() {
+		migrator, err := NewPostgresMigrator(url, "./case/simple_create_table")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = migrator.Migrate()
+		Expect(err).NotTo(HaveOccurred())
+
+		err = migrator.Migrate()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rolls back the failed file", Serial, func() {
+		migrator, err := NewPostgresMigrator(url, "./case/partial_failure")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = migrator.Migrate()
+		Expect(err).To(HaveOccurred())
+
+		db, err := sqlx.Open("postgres", url)
+		Expect(err).NotTo(HaveOccurred())
+		defer db.Close()
+
+		var filenames []string
+		Expect(db.Select(&filenames, "SELECT filename FROM _migrations")).To(Succeed())
+		Expect(filenames).To(Equal([]string{"001_create_table.sql"}))
+
+		var tables []string
+		Expect(db.Select(&tables, "SELECT tablename FROM pg_tables WHERE schemaname = 'public' ORDER BY tablename")).To(Succeed())
+		Expect(tables).To(Equal([]string{"_migrations", "example"}))
+	})
+
+	It("concurrent migrate test", Serial, func() {
+		wg := sync.WaitGroup{}
+		errs := make([]error, 3)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				migrator, err := NewPostgresMigrator(url, "./case/simple_create_table")
+				Expect(err).NotTo(HaveOccurred())
+				errs[i] = migrator.Migrate()
+			}(i)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+})
@@ -0,0 +1,82 @@
+package migration
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// postgresLockKey is the pg_advisory_lock key taken while migrating.
+const postgresLockKey = 0x6d6967726174696f // "migratio"
+
+func init() {
+	Register("postgres", postgresDriver{})
+	Register("postgresql", postgresDriver{})
+}
+
+// PostgresMigrator applies each file in its own transaction. DDL is transactional in PostgreSQL,
+// so a failing file leaves nothing behind. Concurrent migrators wait for each other on an advisory lock.
+type PostgresMigrator struct {
+	*Engine
+}
+
+func NewPostgresMigrator(url string, path string) (*PostgresMigrator, error) {
+	db, dialect, err := postgresDriver{}.Open(url, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return &PostgresMigrator{NewEngine(db, dialect, path)}, nil
+}
+
+type postgresDriver struct{}
+
+// Open takes a URL or keyword/value connection string understood by lib/pq.
+func (postgresDriver) Open(url string, username string, description string) (*sqlx.DB, Dialect, error) {
+	db, err := sqlx.Open("postgres", url)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to database connect")
+	}
+	return db, postgresDialect{}, nil
+}
+
+type postgresDialect struct{}
+
+func (postgresDialect) Lock(ctx context.Context, conn *sqlx.Conn) (func() error, error) {
+	_, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", postgresLockKey)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return func() error {
+		_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", postgresLockKey)
+		return errors.WithStack(err)
+	}, nil
+}
+
+func (postgresDialect) CreateHistoryTable() string {
+	return `
+CREATE TABLE IF NOT EXISTS _migrations (
+    filename VARCHAR(255) NOT NULL,
+    queries TEXT NOT NULL,
+    down_queries TEXT,
+    executed_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (filename)
+)`
+}
+
+// HistoryTableExists looks _migrations up on the search path, as the unqualified queries do.
+func (postgresDialect) HistoryTableExists() string {
+	return "SELECT CASE WHEN to_regclass('_migrations') IS NULL THEN 0 ELSE 1 END"
+}
+
+func (postgresDialect) Begin(ctx context.Context, conn *sqlx.Conn) (Tx, error) {
+	return conn.BeginTxx(ctx, nil)
+}
+
+// Exec sends the whole file as one simple query, so dollar-quoted bodies may contain semicolons.
+func (postgresDialect) Exec(ctx context.Context, e sqlx.ExecerContext, queries string) error {
+	_, err := e.ExecContext(ctx, queries)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,115 @@
+package migration
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/jmoiron/sqlx"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rollback Test", func() {
+	var url string
+	var db *sqlx.DB
+
+	BeforeEach(func() {
+		url = filepath.Join(GinkgoT().TempDir(), "test.db")
+		var err error
+		db, err = sqlx.Open("sqlite", url)
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(db.Close)
+	})
+
+	tables := func() []string {
+		var names []string
+		Expect(db.Select(&names, "SELECT name FROM sqlite_master WHERE type = 'table' AND name != '_migrations' ORDER BY name")).To(Succeed())
+		return names
+	}
+
+	It("migrates to a version and rolls back", func() {
+		m, err := NewSQLiteMigrator(url, "./case/up_down")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(m.MigrateTo(2)).To(Succeed())
+		Expect(tables()).To(Equal([]string{"posts", "users"}))
+		plan, err := m.Plan()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan).To(HaveLen(1))
+		Expect(plan[0].Filename).To(Equal("003_index_users.up.sql"))
+
+		Expect(m.Rollback(1)).To(Succeed())
+		Expect(tables()).To(Equal([]string{"users"}))
+
+		Expect(m.MigrateTo(0)).To(Succeed())
+		Expect(tables()).To(BeEmpty())
+
+		Expect(m.Migrate()).To(Succeed())
+		statuses, err := m.Status()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statuses).To(HaveLen(3))
+		Expect(statuses[0].Reversible).To(BeTrue())
+		Expect(statuses[1].Reversible).To(BeTrue())
+		Expect(statuses[2].Reversible).To(BeFalse())
+	})
+
+	It("refuses to roll back irreversible migrations", func() {
+		m, err := NewSQLiteMigrator(url, "./case/up_down")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.Migrate()).To(Succeed())
+
+		Expect(m.Rollback(2)).To(MatchError(ErrIrreversible))
+		Expect(m.MigrateTo(1)).To(MatchError(ErrIrreversible))
+
+		plan, err := m.Plan()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(plan).To(BeEmpty())
+		Expect(tables()).To(Equal([]string{"posts", "users"}))
+	})
+
+	It("rolls back with the down queries recorded at apply time", func() {
+		path := GinkgoT().TempDir()
+		for _, name := range []string{"001_create_users.up.sql", "001_create_users.down.sql", "002_create_posts.up.sql", "002_create_posts.down.sql"} {
+			content, err := os.ReadFile(filepath.Join("./case/up_down", name))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(filepath.Join(path, name), content, 0o644)).To(Succeed())
+		}
+
+		m, err := NewSQLiteMigrator(url, path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.Migrate()).To(Succeed())
+
+		Expect(os.Remove(filepath.Join(path, "002_create_posts.down.sql"))).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(path, "001_create_users.down.sql"), []byte("syntax error"), 0o644)).To(Succeed())
+
+		Expect(m.Rollback(5)).To(Succeed())
+		Expect(tables()).To(BeEmpty())
+	})
+
+	It("adds the down queries column to an old history table", func() {
+		_, err := db.Exec(`CREATE TABLE _migrations (
+    filename VARCHAR(255) NOT NULL,
+    queries TEXT NOT NULL,
+    executed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (filename)
+)`)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = db.Exec("INSERT INTO _migrations (filename, queries) VALUES ('000_old.sql', '')")
+		Expect(err).NotTo(HaveOccurred())
+
+		m, err := NewSQLiteMigrator(url, "./case/up_down")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.MigrateTo(2)).To(Succeed())
+		Expect(m.Rollback(2)).To(Succeed())
+		Expect(m.Rollback(1)).To(MatchError(ErrIrreversible))
+	})
+
+	It("rejects down files without up files", func() {
+		path := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(path, "001_a.down.sql"), []byte("drop table a;"), 0o644)).To(Succeed())
+
+		m, err := NewSQLiteMigrator(url, path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(m.Migrate()).To(MatchError(ContainSubstring("001_a.down.sql has no up file")))
+	})
+})
@@ -0,0 +1,86 @@
+package migration
+
+import (
+	"path/filepath"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SQLite Migration Test", func() {
+	var url string
+
+	BeforeEach(func() {
+		url = filepath.Join(GinkgoT().TempDir(), "test.db")
+	})
+
+	It("simple create table test", func() {
+		migrator, err := NewSQLiteMigrator(url, "./case/simple_create_table")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = migrator.Migrate()
+		Expect(err).NotTo(HaveOccurred())
+
+		err = migrator.Migrate()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rolls back the failed file", func() {
+		migrator, err := NewSQLiteMigrator(url, "./case/partial_failure")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = migrator.Migrate()
+		Expect(err).To(HaveOccurred())
+
+		db, err := sqlx.Open("sqlite", url)
+		Expect(err).NotTo(HaveOccurred())
+		defer db.Close()
+
+		var filenames []string
+		Expect(db.Select(&filenames, "SELECT filename FROM _migrations")).To(Succeed())
+		Expect(filenames).To(Equal([]string{"001_create_table.sql"}))
+
+		var tables []string
+		Expect(db.Select(&tables, "SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name")).To(Succeed())
+		Expect(tables).To(Equal([]string{"_migrations", "example"}))
+	})
+
+	It("concurrent migrate test", func() {
+		wg := sync.WaitGroup{}
+		errs := make([]error, 3)
+		for i := range errs {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+
+				migrator, err := NewSQLiteMigrator(url, "./case/simple_create_table")
+				Expect(err).NotTo(HaveOccurred())
+				errs[i] = migrator.Migrate()
+			}(i)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			Expect(err).NotTo(HaveOccurred())
+		}
+	})
+
+	It("opens an in-memory database with the migrations applied", func() {
+		db, err := OpenSQLiteInMemory("./case/simple_create_table")
+		Expect(err).NotTo(HaveOccurred())
+		defer db.Close()
+
+		_, err = db.Exec("INSERT INTO example (id, name) VALUES ('1', 'a')")
+		Expect(err).NotTo(HaveOccurred())
+
+		var names []string
+		Expect(db.Select(&names, "SELECT name FROM example")).To(Succeed())
+		Expect(names).To(Equal([]string{"a"}))
+
+		_, err = OpenSQLiteInMemory("./case/partial_failure")
+		Expect(err).To(HaveOccurred())
+	})
+})
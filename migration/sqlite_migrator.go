@@ -0,0 +1,124 @@
+package migration
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	_ "modernc.org/sqlite"
+)
+
+func init() {
+	Register("sqlite", sqliteDriver{})
+}
+
+// SQLiteMigrator applies each file in a transaction that takes the database write lock up front,
+// so concurrent migrators apply every file once.
+type SQLiteMigrator struct {
+	*Engine
+}
+
+// NewSQLiteMigrator opens url, a file name or URI understood by modernc.org/sqlite.
+func NewSQLiteMigrator(url string, path string) (*SQLiteMigrator, error) {
+	db, dialect, err := sqliteDriver{}.Open(url, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return &SQLiteMigrator{NewEngine(db, dialect, path)}, nil
+}
+
+// OpenSQLiteInMemory opens an in-memory database with the files in path applied.
+// It is limited to one connection, since every connection to ":memory:" is a database of its own.
+func OpenSQLiteInMemory(path string) (*sqlx.DB, error) {
+	db, err := sqlx.Open("sqlite", ":memory:")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to database connect")
+	}
+	db.SetMaxOpenConns(1)
+	db.SetConnMaxLifetime(0)
+	db.SetConnMaxIdleTime(0)
+
+	err = NewEngine(db, sqliteDialect{}, path).Migrate()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+type sqliteDriver struct{}
+
+// Open takes a file name or URI, optionally prefixed with sqlite://. Unless the URL sets a busy timeout,
+// every connection waits for the write lock like Lock does, so reading the history during a migration does not fail.
+func (sqliteDriver) Open(url string, username string, description string) (*sqlx.DB, Dialect, error) {
+	dsn := strings.TrimPrefix(url, "sqlite://")
+	if !strings.Contains(dsn, "busy_timeout") {
+		sep := "?"
+		if strings.Contains(dsn, "?") {
+			sep = "&"
+		}
+		dsn += sep + "_pragma=busy_timeout(10000)"
+	}
+
+	db, err := sqlx.Open("sqlite", dsn)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to database connect")
+	}
+	return db, sqliteDialect{}, nil
+}
+
+type sqliteDialect struct{}
+
+// Lock only makes the connection wait for the write lock, which each transaction takes in Begin.
+func (sqliteDialect) Lock(ctx context.Context, conn *sqlx.Conn) (func() error, error) {
+	_, err := conn.ExecContext(ctx, "PRAGMA busy_timeout = 10000")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return func() error { return nil }, nil
+}
+
+func (sqliteDialect) CreateHistoryTable() string {
+	return `
+CREATE TABLE IF NOT EXISTS _migrations (
+    filename VARCHAR(255) NOT NULL,
+    queries TEXT NOT NULL,
+    down_queries TEXT,
+    executed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (filename)
+)`
+}
+
+func (sqliteDialect) HistoryTableExists() string {
+	return "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = '_migrations'"
+}
+
+// Begin starts an IMMEDIATE transaction, which database/sql cannot do, so it is managed by hand on the connection.
+func (sqliteDialect) Begin(ctx context.Context, conn *sqlx.Conn) (Tx, error) {
+	_, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &sqliteTx{conn, ctx}, nil
+}
+
+func (sqliteDialect) Exec(ctx context.Context, e sqlx.ExecerContext, queries string) error {
+	_, err := e.ExecContext(ctx, queries)
+	return errors.WithStack(err)
+}
+
+type sqliteTx struct {
+	*sqlx.Conn
+	ctx context.Context
+}
+
+func (tx *sqliteTx) Commit() error {
+	_, err := tx.ExecContext(tx.ctx, "COMMIT")
+	return errors.WithStack(err)
+}
+
+func (tx *sqliteTx) Rollback() error {
+	_, err := tx.ExecContext(tx.ctx, "ROLLBACK")
+	return errors.WithStack(err)
+}